@@ -1,25 +1,512 @@
 package chesskimo
 
-// // AlphaBeta runs a minmaxing search with alpha-beta pruning for the given parameters.
-// // It returns the best move for a full search of a given max depth. If a depth search has to
-// // be cancelled, the result of the next best depth is returned.
-// func AlphaBeta(engine *Engine, ss *SearchSettings, dostop *uint32) SearchResult {
-// 	startTime := time.Now()
-// 	maxTime := 5 * time.Second
-// 	sr := SearchResult{Move: BitMove(0)}
+import (
+	"fmt"
+	"sync/atomic"
+	"time"
+)
 
-// 	sr.Depth = ss.MaxDepth
-// 	valid := alphaBeta(engine, ss.MaxDepth, -INFINITY, INFINITY, dostop, &startTime, maxTime, &sr)
-// 	if valid {
+const (
+	// MAX_PLY limits the depth of any search.
+	MAX_PLY = 128
+	// MATE_SCORE is the score for a checkmate on the board. Mates deeper in the tree
+	// are reduced by their distance to the root, so shorter mates are preferred.
+	MATE_SCORE = 100000
+	// MATE_BOUND is the lowest score that still denotes a forced mate.
+	MATE_BOUND = MATE_SCORE - MAX_PLY
 
-// 	}
+	// ASPIRATION_WINDOW is the initial distance of the search window from the score of
+	// the previous iteration. Iterations before ASPIRATION_MIN_DEPTH use a full window.
+	ASPIRATION_WINDOW    = 25
+	ASPIRATION_MIN_DEPTH = 5
 
-// 	return sr
-// }
+	// check the stop conditions every 'stop_check_interval' nodes.
+	stop_check_interval = 2048
+	// report the progress of long iterations every 'info_interval'.
+	info_interval = time.Second
+)
 
-// func alphaBeta(engine *Engine, depth, alpha, beta int, dostop *uint32, startTime *time.Time, maxTime time.Duration, result *SearchResult) bool {
-// 	mlist := MoveList{}
-// 	board := engine.board
+// abSearch holds the state of one alpha-beta search.
+type abSearch struct {
+	engine  *Engine
+	board   Board
+	history MoveHistory
+	tt      *TranspositionTable
+	dostop  *uint32
+	tm      TimeManager
+	ss      *SearchSettings
+	nodes   uint64
+	stopped bool
 
-// 	return true
-// }
+	order       *MoveOrderer
+	selectivity Selectivity
+	// moves contains the move played at every ply of the current line.
+	moves [MAX_PLY + 1]BitMove
+	// pv is the triangular principal variation table: pv[ply][ply:pvLen[ply]] is the
+	// best line found from the node at ply on.
+	pv    [MAX_PLY + 1][MAX_PLY + 1]BitMove
+	pvLen [MAX_PLY + 1]int
+	// quiets contains the quiet moves searched without a cutoff at every ply.
+	quiets [MAX_PLY + 1][max_movelist_size]BitMove
+	// Beta cutoffs and the cutoffs caused by the first move searched measure the move ordering.
+	cutoffs      uint64
+	firstCutoffs uint64
+
+	// depth of the current iteration and the maximum ply reached in it.
+	depth    int
+	seldepth int
+	// time of the last progress report.
+	lastInfo time.Duration
+}
+
+// AlphaBetaSearch runs an iterative deepening principal variation search with aspiration
+// windows and returns the best move and line of the deepest completed iteration. The search stops when
+// a limit of the search settings is reached or dostop is set to a non-zero value.
+func AlphaBetaSearch(engine *Engine, ss *SearchSettings, dostop *uint32) SearchResult {
+	s := abSearch{
+		engine:  engine,
+		board:   engine.board,
+		history: engine.history.Copy(),
+		tt:      engine.tt,
+		dostop:  dostop,
+		tm:      engine.newTimeManager(ss),
+		ss:      ss,
+		order:   NewMoveOrderer(),
+
+		selectivity: engine.selectivity,
+	}
+	s.history.StartSearch()
+	sr := SearchResult{Move: BitMove(0)}
+	s.tt.NewSearch()
+
+	maxDepth := ss.MaxDepth
+	if maxDepth <= 0 || maxDepth > MAX_PLY {
+		maxDepth = MAX_PLY
+	}
+	if ss.Mate > 0 && 2*ss.Mate-1 < maxDepth {
+		// A mate in n moves is found within 2n-1 plies.
+		maxDepth = 2*ss.Mate - 1
+	}
+
+	mlist := MoveList{}
+	s.board.GenerateAllLegalMoves(&mlist)
+	if mlist.Size == 0 {
+		// Checkmate or stalemate -> there is nothing to search.
+		return sr
+	}
+	// If the first iteration is already interrupted we still have a legal move.
+	sr.Move = mlist.Moves[0]
+
+	// With MultiPV the best moves are searched one after another, each
+	// search excluding the moves found before.
+	multiPV := engine.multiPV
+	if multiPV < 1 {
+		multiPV = 1
+	} else if multiPV > int(mlist.Size) {
+		multiPV = int(mlist.Size)
+	}
+
+	// The scores of the previous iteration center the aspiration windows.
+	prevScores := make([]int, multiPV)
+	for depth := 1; depth <= maxDepth; depth++ {
+		s.depth, s.seldepth = depth, 0
+		bestScore := 0
+		for pvIdx := 0; pvIdx < multiPV; pvIdx++ {
+			move, score := s.searchAspiration(&mlist, depth, uint32(pvIdx), prevScores[pvIdx])
+			if s.stopped {
+				break
+			}
+			prevScores[pvIdx] = score
+			pv := make([]BitMove, s.pvLen[0])
+			copy(pv, s.pv[0][:s.pvLen[0]])
+			if pvIdx == 0 {
+				sr.Move, sr.Score, sr.Depth = move, score, depth
+				sr.PV, sr.SelDepth = pv, s.seldepth
+				bestScore = score
+			}
+
+			engine.logger.Printf("depth %d score %d nodes %d move %s first cutoffs %.1f%%", depth, score, s.nodes, move.MiniNotation(), s.firstCutoffRate()*100)
+			s.lastInfo = s.tm.Elapsed()
+			info := SearchInfo{
+				Depth:    depth,
+				SelDepth: s.seldepth,
+				Score:    score,
+				Nodes:    s.nodes,
+				Time:     s.lastInfo,
+				HashFull: s.tt.Hashfull(),
+				PV:       pv,
+			}
+			if multiPV > 1 {
+				info.MultiPV = pvIdx + 1
+			}
+			engine.sendInfo(info)
+		}
+		if s.stopped {
+			// Results of an interrupted iteration are not reliable.
+			break
+		}
+
+		if multiPV == 1 && (bestScore >= MATE_BOUND || bestScore <= -MATE_BOUND) {
+			// A forced mate was found. Searching deeper cannot change that.
+			break
+		}
+		if s.tm.SoftLimitReached() {
+			// The next iteration would most likely not finish in time.
+			break
+		}
+	}
+
+	if len(sr.PV) == 0 {
+		sr.PV = []BitMove{sr.Move}
+	}
+	sr.Nodes, sr.Time = s.nodes, s.tm.Elapsed()
+	// The share of cutoffs on the first move measures the move ordering.
+	rate := fmt.Sprintf("first move cutoffs %.1f%% of %d", s.firstCutoffRate()*100, s.cutoffs)
+	engine.logger.Print(rate)
+	if engine.debugging() {
+		engine.sendInfo(SearchInfo{String: rate})
+	}
+	return sr
+}
+
+// searchAspiration searches the root moves from index 'first' on with a narrow window
+// around the score of the previous iteration. If the score falls outside the window,
+// the window is widened on that side and the moves are searched again.
+func (s *abSearch) searchAspiration(mlist *MoveList, depth int, first uint32, prev int) (BitMove, int) {
+	alpha, beta := -INFINITY, INFINITY
+	delta := ASPIRATION_WINDOW
+	if depth >= ASPIRATION_MIN_DEPTH && prev > -MATE_BOUND && prev < MATE_BOUND {
+		alpha, beta = prev-delta, prev+delta
+	}
+
+	for {
+		move, score := s.searchRoot(mlist, depth, first, alpha, beta)
+		if s.stopped {
+			return move, score
+		}
+		if score <= alpha {
+			// Fail low: the position is worse than expected.
+			alpha = score - delta
+			if alpha < -INFINITY {
+				alpha = -INFINITY
+			}
+		} else if score >= beta {
+			// Fail high: the position is better than expected.
+			beta = score + delta
+			if beta > INFINITY {
+				beta = INFINITY
+			}
+		} else {
+			return move, score
+		}
+		delta *= 2
+	}
+}
+
+// searchRoot searches the root moves from index 'first' on to the given depth within the
+// window (alpha, beta) and returns the best one with its score. The principal variation is
+// left in s.pv[0]. The moves before 'first' are excluded, they are the better variations of
+// MultiPV. The best move is moved to index 'first' so it is searched first next time.
+func (s *abSearch) searchRoot(mlist *MoveList, depth int, first uint32, alpha, beta int) (BitMove, int) {
+	origAlpha := alpha
+	best := -INFINITY
+	bestIdx := first
+	s.pvLen[0] = 0
+
+	for i := first; i < mlist.Size; i++ {
+		move := mlist.Moves[i]
+		s.history.Push(s.board.Hash)
+		s.moves[0] = move
+		undo := s.board.MakeLegalMove(move)
+		score := 0
+		if i == first {
+			score = -s.negamax(depth-1, 1, -beta, -alpha)
+		} else {
+			// Principal variation search: a null window proves that the move is not
+			// better than the best so far. Only if it is, the move is searched again.
+			score = -s.negamax(depth-1, 1, -alpha-1, -alpha)
+			if score > alpha && score < beta {
+				score = -s.negamax(depth-1, 1, -beta, -alpha)
+			}
+		}
+		s.board.UnmakeMove(undo)
+		s.history.Pop()
+
+		if s.stopped {
+			break
+		}
+		if score > best {
+			best = score
+			if score > alpha {
+				alpha = score
+				bestIdx = i
+				s.updatePV(0, move)
+				if alpha >= beta {
+					break
+				}
+			}
+		}
+	}
+
+	bestMove := mlist.Moves[bestIdx]
+	copy(mlist.Moves[first+1:bestIdx+1], mlist.Moves[first:bestIdx])
+	mlist.Moves[first] = bestMove
+	if !s.stopped && first == 0 && best > origAlpha && best < beta {
+		// Only the search of all moves with an exact score gives the score of the position.
+		s.tt.Store(s.board.Hash, bestMove, best, depth, 0, BOUND_EXACT)
+	}
+
+	return bestMove, best
+}
+
+// updatePV makes move followed by the principal variation of the next ply the
+// principal variation of the node at ply.
+func (s *abSearch) updatePV(ply int, move BitMove) {
+	s.pv[ply][ply] = move
+	n := copy(s.pv[ply][ply+1:], s.pv[ply+1][ply+1:s.pvLen[ply+1]])
+	s.pvLen[ply] = ply + 1 + n
+}
+
+// negamax searches the current position to the given depth and returns
+// its score relative to the player to move.
+func (s *abSearch) negamax(depth, ply, alpha, beta int) int {
+	if depth <= 0 {
+		// The horizon is reached, only captures are searched further.
+		return s.quiescence(ply, alpha, beta)
+	}
+
+	s.pvLen[ply] = ply
+	s.nodes++
+	if s.nodes%stop_check_interval == 0 {
+		s.checkStop()
+	}
+	if s.stopped {
+		return 0
+	}
+	if ply > s.seldepth {
+		s.seldepth = ply
+	}
+	if s.history.IsSearchDraw(&s.board) {
+		// The position repeats, the player to move can force a draw.
+		return 0
+	}
+
+	if ply >= MAX_PLY {
+		return Evaluate(&s.board)
+	}
+
+	// Nodes searched with a full window may become part of the principal variation.
+	pvNode := beta-alpha > 1
+
+	// A previous search of this position may already answer this node.
+	// Nodes of the principal variation are always searched to keep the line complete.
+	ttMove := BitMove(0)
+	if entry, ok := s.tt.Probe(s.board.Hash); ok {
+		ttMove = entry.Move
+		if !pvNode && int(entry.Depth) >= depth {
+			score := ScoreFromTT(int(entry.Score), ply)
+			switch entry.Bound {
+			case BOUND_EXACT:
+				return score
+			case BOUND_LOWER:
+				if score >= beta {
+					return score
+				}
+			case BOUND_UPPER:
+				if score <= alpha {
+					return score
+				}
+			}
+		}
+	}
+
+	if s.board.DrawCounter >= 100 || s.board.InsufficientMaterial() {
+		// Draw by the fifty-move rule or because nobody can win anymore.
+		// Only a checkmate on the board has precedence.
+		if _, reason := s.board.Result(nil); reason != REASON_CHECKMATE {
+			return 0
+		}
+	}
+
+	prev := s.moves[ply-1]
+	inCheck := s.board.InCheck()
+	// Pruning is only safe outside of the principal variation, if the position
+	// is not in check and no mate is in sight.
+	canPrune := !pvNode && !inCheck && alpha > -MATE_BOUND && beta < MATE_BOUND
+	eval := 0
+	if canPrune {
+		eval = Evaluate(&s.board)
+	}
+
+	// Reverse futility pruning: the static evaluation is so far above beta that
+	// no move of the opponent is expected to bring it back down.
+	if s.selectivity.ReverseFutility && canPrune && depth <= RFP_MAX_DEPTH && eval-RFP_MARGIN*depth >= beta {
+		return eval - RFP_MARGIN*depth
+	}
+
+	// Null-move pruning: if passing the turn still fails high, a real move will as well.
+	// Positions without pieces are skipped because of zugzwang, two null moves in a row
+	// are skipped because they would only reduce the depth.
+	if s.selectivity.NullMove && canPrune && depth >= NULL_MOVE_MIN_DEPTH && prev != BitMove(0) &&
+		eval >= beta && s.board.HasNonPawnMaterial() {
+		s.history.Push(s.board.Hash)
+		s.moves[ply] = BitMove(0)
+		undo := s.board.MakeNullMove()
+		score := -s.negamax(depth-1-nullMoveReduction(depth), ply+1, -beta, -beta+1)
+		s.board.UnmakeNullMove(undo)
+		s.history.Pop()
+
+		if s.stopped {
+			return 0
+		}
+		if score >= beta {
+			// Mate scores from a null move search are not proven.
+			return beta
+		}
+	}
+
+	// Futility pruning: quiet moves cannot raise the score to alpha near the horizon.
+	futile := s.selectivity.Futility && canPrune && depth <= FUTILITY_MAX_DEPTH && eval+FUTILITY_MARGIN*depth <= alpha
+	futilityValue := eval + FUTILITY_MARGIN*depth
+
+	// The best move of a previous search is searched first, then the moves
+	// that are most likely to cause a cutoff.
+	picker := NewMovePicker(&s.board, s.order, ttMove, ply, prev)
+
+	origAlpha := alpha
+	best := -INFINITY
+	bestMove := BitMove(0)
+	// The quiet moves searched without a cutoff.
+	quiets := s.quiets[ply][:0]
+	// moves counts all legal moves, searched only the moves that were not pruned.
+	moves, searched := 0, 0
+	for move := picker.Next(); move != BitMove(0); move = picker.Next() {
+		quiet := captureGain(&s.board, move) == 0
+		moves++
+
+		s.history.Push(s.board.Hash)
+		s.moves[ply] = move
+		undo := s.board.MakeLegalMove(move)
+		givesCheck := s.board.InCheck()
+
+		// Late move pruning: quiet moves late in the order rarely cut off at shallow depths.
+		// Checks are kept, they may lead to a mate.
+		if s.selectivity.LateMovePruning && canPrune && quiet && !givesCheck && depth <= LMP_MAX_DEPTH && moves > lateMoveCount(depth) {
+			s.board.UnmakeMove(undo)
+			s.history.Pop()
+			continue
+		}
+
+		if futile && quiet && !givesCheck && moves > 1 {
+			s.board.UnmakeMove(undo)
+			s.history.Pop()
+			if futilityValue > best {
+				best = futilityValue
+			}
+			continue
+		}
+		searched++
+
+		// Late move reductions: late quiet moves are searched with a reduced depth.
+		reduction := 0
+		if s.selectivity.LMR && depth >= LMR_MIN_DEPTH && moves > LMR_MIN_MOVES && quiet && !inCheck && !givesCheck {
+			reduction = lmrReduction(depth, moves)
+		}
+		score := 0
+		if searched == 1 {
+			score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+		} else {
+			// Principal variation search: all moves after the first are searched with a null
+			// window. Only if a move beats alpha it is searched again to full depth and
+			// then, in a node of the principal variation, with the full window.
+			score = -s.negamax(depth-1-reduction, ply+1, -alpha-1, -alpha)
+			if score > alpha && reduction > 0 {
+				score = -s.negamax(depth-1, ply+1, -alpha-1, -alpha)
+			}
+			if score > alpha && score < beta {
+				score = -s.negamax(depth-1, ply+1, -beta, -alpha)
+			}
+		}
+		s.board.UnmakeMove(undo)
+		s.history.Pop()
+
+		if s.stopped {
+			return 0
+		}
+		if score > best {
+			best = score
+			bestMove = move
+			if score > alpha {
+				alpha = score
+				if pvNode {
+					s.updatePV(ply, move)
+				}
+				if alpha >= beta {
+					// Beta cutoff.
+					s.cutoffs++
+					if searched == 1 {
+						s.firstCutoffs++
+					}
+					if quiet {
+						s.order.Update(&s.board, move, quiets, depth, ply, prev)
+					}
+					break
+				}
+			}
+		}
+		if quiet {
+			quiets = append(quiets, move)
+		}
+	}
+
+	if moves == 0 {
+		if inCheck {
+			// Checkmate.
+			return -MATE_SCORE + ply
+		}
+		// Stalemate.
+		return 0
+	}
+
+	bound := BOUND_EXACT
+	if best >= beta {
+		bound = BOUND_LOWER
+	} else if best <= origAlpha {
+		// No move raised alpha, so there is no reliable best move.
+		bound = BOUND_UPPER
+		bestMove = BitMove(0)
+	}
+	s.tt.Store(s.board.Hash, bestMove, best, depth, ply, bound)
+
+	return best
+}
+
+// firstCutoffRate returns the share of beta cutoffs that were caused by the first move
+// searched in a node. The better the move ordering, the closer it is to 1.
+func (s *abSearch) firstCutoffRate() float64 {
+	if s.cutoffs == 0 {
+		return 0
+	}
+	return float64(s.firstCutoffs) / float64(s.cutoffs)
+}
+
+// checkStop tests if the search was told to stop or reached its time or node limit.
+// It also reports the progress of long iterations.
+func (s *abSearch) checkStop() {
+	if atomic.LoadUint32(s.dostop) != 0 || s.tm.HardLimitReached() {
+		s.stopped = true
+	} else if s.ss.Nodes > 0 && s.nodes >= s.ss.Nodes {
+		s.stopped = true
+	}
+
+	if elapsed := s.tm.Elapsed(); elapsed-s.lastInfo >= info_interval {
+		s.lastInfo = elapsed
+		s.engine.sendInfo(SearchInfo{
+			Depth:    s.depth,
+			SelDepth: s.seldepth,
+			Nodes:    s.nodes,
+			Time:     elapsed,
+			HashFull: s.tt.Hashfull(),
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestAlphaBetaFindsMate tests if the alpha-beta search finds forced mates.
+func TestAlphaBetaFindsMate(t *testing.T) {
+	type set struct {
+		Fen   string
+		Depth int
+		Moves []string
+		Score int
+	}
+	testsets := []set{
+		// Back rank mate in 1.
+		{Fen: "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", Depth: 3, Moves: []string{"a1a8"}, Score: MATE_SCORE - 1},
+		// Rook ladder mate in 2.
+		{Fen: "7k/8/R7/8/8/8/8/1R5K w - - 0 1", Depth: 4, Moves: []string{"b1b7", "a6a7"}, Score: MATE_SCORE - 3},
+	}
+
+	for _, set := range testsets {
+		engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+		if err := engine.board.SetFEN(set.Fen); err != nil {
+			t.Fatalf(err.Error())
+		}
+		dostop := uint32(0)
+		sr := AlphaBetaSearch(engine, &SearchSettings{MaxDepth: set.Depth}, &dostop)
+		found := false
+		for _, m := range set.Moves {
+			if sr.Move.MiniNotation() == m {
+				found = true
+			}
+		}
+		if !found {
+			t.Fatalf("Expected one of the moves %v for FEN %s but got %s\n", set.Moves, set.Fen, sr.Move.MiniNotation())
+		}
+		if sr.Score != set.Score {
+			t.Fatalf("Expected score %d for FEN %s but got %d\n", set.Score, set.Fen, sr.Score)
+		}
+	}
+}
+
+// TestPrincipalVariation tests if the search result contains a legal principal variation
+// that starts with the best move and leads to the mate of a mate score.
+func TestPrincipalVariation(t *testing.T) {
+	type set struct {
+		Fen   string
+		Depth int
+		// Length is the expected length of the PV or 0 if it is not known.
+		Length int
+	}
+	testsets := []set{
+		{Fen: "6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", Depth: 3, Length: 1},
+		{Fen: "7k/8/R7/8/8/8/8/1R5K w - - 0 1", Depth: 4, Length: 3},
+		{Fen: "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", Depth: 6},
+		{Fen: "r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1", Depth: 6},
+	}
+
+	for _, set := range testsets {
+		engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+		if err := engine.board.SetFEN(set.Fen); err != nil {
+			t.Fatalf(err.Error())
+		}
+		dostop := uint32(0)
+		sr := AlphaBetaSearch(engine, &SearchSettings{MaxDepth: set.Depth}, &dostop)
+
+		if len(sr.PV) == 0 || sr.PV[0] != sr.Move {
+			t.Fatalf("Expected a PV starting with %s for FEN %s but got %v\n", sr.Move.MiniNotation(), set.Fen, sr.PV)
+		}
+		if set.Length > 0 && len(sr.PV) != set.Length {
+			t.Fatalf("Expected a PV of length %d for FEN %s but got %d\n", set.Length, set.Fen, len(sr.PV))
+		}
+		board := engine.board
+		for _, m := range sr.PV {
+			if !board.IsLegal(m) {
+				t.Fatalf("PV move %s is illegal for FEN %s\n", m.MiniNotation(), set.Fen)
+			}
+			board.MakeLegalMove(m)
+		}
+		if sr.Nodes == 0 || sr.SelDepth < sr.Depth {
+			t.Fatalf("Expected nodes and a seldepth of at least %d for FEN %s but got %d and %d\n", sr.Depth, set.Fen, sr.Nodes, sr.SelDepth)
+		}
+	}
+}
@@ -26,8 +26,8 @@ const (
 	COLOR_ONLY_MASK Color = 1   // 00000001
 	COLOR_TEST_MASK Color = 129 // 10000001
 
-	DARK  Color = iota // 0
-	LIGHT              // 1
+	DARK  Color = 0
+	LIGHT Color = 1
 
 	// Pieces:
 	EMPTY        Piece = 128 // 10000000
@@ -48,6 +48,32 @@ const (
 	GAMESTATE_WHITE_WIN State = 1
 )
 
+// ResultReason tells why a game has ended.
+type ResultReason uint8
+
+const (
+	REASON_NONE ResultReason = iota
+	REASON_CHECKMATE
+	REASON_STALEMATE
+	// The fifty-move rule allows to claim a draw, the seventy-five-move rule ends the game.
+	REASON_FIFTY_MOVES
+	REASON_SEVENTY_FIVE_MOVES
+	REASON_INSUFFICIENT_MATERIAL
+)
+
+var reasonNames = [...]string{
+	REASON_NONE:                  "none",
+	REASON_CHECKMATE:             "checkmate",
+	REASON_STALEMATE:             "stalemate",
+	REASON_FIFTY_MOVES:           "fifty-move rule",
+	REASON_SEVENTY_FIVE_MOVES:    "seventy-five-move rule",
+	REASON_INSUFFICIENT_MATERIAL: "insufficient material",
+}
+
+func (r ResultReason) String() string {
+	return reasonNames[r]
+}
+
 const (
 	// occupancy types by color and piece
 	BPAWN   Piece = PAWN | BLACK
@@ -202,8 +228,8 @@ func (sq Square) OnBoard() bool {
 }
 
 func (sq Square) SquareColor() Color {
-	// Dark squares have an even index, light squares have an odd one.
-	return sq & 1
+	// Dark squares have an even sum of rank and file, light squares have an odd one.
+	return (sq.Rank() + sq.File()) & 1
 }
 
 func (sq Square) Rank() Square {
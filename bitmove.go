@@ -11,8 +11,8 @@ package chesskimo
 // The from and to squares are actual 0x88 indexes (0-127) and the promotion
 // field contains 1 bit for each possible type: knight, bishop, rook, queen.
 //
-// TODO this type should be enhanced by an additional value field in the future
-// which allows better move ordering in the move search.
+// The ordering scores of moves are kept in MoveList.Scores and not in the move itself,
+// so that moves can still be compared with '=='.
 type BitMove uint32
 
 const (
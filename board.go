@@ -21,6 +21,9 @@ import (
 //
 // All indexes shown are in HEX. The left board represents the actual playing board, whereas there right board
 // is for detection of illegal moves without heavy conditional usage.
+//
+// MoveNumber counts the half moves (plies) since the start of the game. The full move number
+// of a FEN record is derived from it.
 type Board struct {
 	Squares     [64 * 2]Piece
 	CastleShort [2]bool
@@ -37,6 +40,7 @@ type Board struct {
 	Bishops     [2]PieceList
 	Knights     [2]PieceList
 	Pawns       [2]PieceList
+	Hash        uint64
 }
 
 const (
@@ -45,6 +49,26 @@ const (
 	CHECK_CHECKMATE    = 0x1F
 )
 
+// GenType selects which legal moves the move generators emit.
+type GenType uint8
+
+const (
+	// GEN_CAPTURES selects all captures (including en passent) and all promotions.
+	GEN_CAPTURES GenType = 1 << iota
+	// GEN_QUIETS selects all other moves, including castling.
+	GEN_QUIETS
+	// GEN_ALL selects every legal move.
+	GEN_ALL = GEN_CAPTURES | GEN_QUIETS
+)
+
+// selects returns true if gen contains a move without promotion to a square holding 'target'.
+func (gen GenType) selects(target Piece) bool {
+	if target.IsEmpty() {
+		return gen&GEN_QUIETS != 0
+	}
+	return gen&GEN_CAPTURES != 0
+}
+
 var (
 	// Lookup0x88 maps the indexes of a 8x8 MinBoard to the 0x88 board indexes.
 	Lookup0x88 = [64]Square{
@@ -175,6 +199,12 @@ func (b *Board) SetFEN(fenstr string) error {
 		return err
 	}
 
+	b.SetMinBoard(mb)
+	return nil
+}
+
+// SetMinBoard sets the position of a MinBoard, e.g. one parsed from a FEN or EPD record.
+func (b *Board) SetMinBoard(mb MinBoard) {
 	for color := BLACK; color <= WHITE; color++ {
 		b.Sliders[color].Clear()
 		b.Queens[color].Clear()
@@ -185,7 +215,14 @@ func (b *Board) SetFEN(fenstr string) error {
 	}
 
 	// No error encountered -> set all members of the board instance.
-	b.MoveNumber = mb.MoveNum
+	// A full move number of 0 is invalid but common, it is treated as 1.
+	b.MoveNumber = 0
+	if mb.MoveNum > 0 {
+		b.MoveNumber = 2 * (mb.MoveNum - 1)
+	}
+	if mb.Color == BLACK {
+		b.MoveNumber++
+	}
 	b.DrawCounter = mb.HalfMoves
 
 	if mb.EpSquare != OTB {
@@ -235,10 +272,35 @@ func (b *Board) SetFEN(fenstr string) error {
 		}
 	}
 
+	b.Hash = b.ComputeHash()
+
 	// Set info board and find possible checks.
 	b.DetectChecksAndPins(b.Player)
+}
 
-	return nil
+// FEN returns the FEN record of the current position.
+func (b *Board) FEN() string {
+	mb := b.ToMinBoard()
+	return mb.ToFEN()
+}
+
+// ToMinBoard converts the board into a MinBoard.
+func (b *Board) ToMinBoard() MinBoard {
+	mb := NewMinBoard()
+	for idx, sq := range Lookup0x88 {
+		mb.Squares[idx] = b.Squares[sq]
+	}
+	mb.Color = b.Player
+	mb.CastleShort = b.CastleShort
+	mb.CastleLong = b.CastleLong
+	mb.EpSquare = OTB
+	if b.EpSquare != OTB {
+		mb.EpSquare = b.EpSquare.To8x8()
+	}
+	mb.HalfMoves = b.DrawCounter
+	mb.MoveNum = b.MoveNumber/2 + 1
+
+	return mb
 }
 
 func (b *Board) clearMetaInfo() {
@@ -311,18 +373,102 @@ func (b *Board) clearMetaInfo() {
 	b.Squares[0x7f] = INFO_NONE
 }
 
-//func (b *Board) Result(mlist *MoveList) State {
+// Result tells if the game has ended in the current position and why. mlist must
+// contain all legal moves of the position, if it is nil they are generated.
+// A game that can be claimed as draw by the fifty-move rule is reported as draw.
+func (b *Board) Result(mlist *MoveList) (State, ResultReason) {
+	if mlist == nil {
+		mlist = &MoveList{}
+		b.GenerateAllLegalMoves(mlist)
+	}
 
-//}
+	if mlist.Size == 0 {
+		if b.CheckInfo == CHECK_NONE {
+			return GAMESTATE_DRAW, REASON_STALEMATE
+		}
+		// The player to move is checkmated.
+		return State(b.Player.Flip()), REASON_CHECKMATE
+	}
+
+	if b.DrawCounter >= 150 {
+		return GAMESTATE_DRAW, REASON_SEVENTY_FIVE_MOVES
+	} else if b.DrawCounter >= 100 {
+		return GAMESTATE_DRAW, REASON_FIFTY_MOVES
+	}
+	if b.InsufficientMaterial() {
+		return GAMESTATE_DRAW, REASON_INSUFFICIENT_MATERIAL
+	}
+
+	return GAMESTATE_ONGOING, REASON_NONE
+}
+
+// InsufficientMaterial returns true if no sequence of moves can lead to a checkmate.
+// This is the case for king against king with at most one minor piece, or if all
+// remaining minor pieces are bishops on squares of the same color.
+func (b *Board) InsufficientMaterial() bool {
+	minors := 0
+	for color := BLACK; color <= WHITE; color++ {
+		if b.Pawns[color].Size > 0 || b.Rooks[color].Size > 0 || b.Queens[color].Size > 0 {
+			return false
+		}
+		minors += int(b.Knights[color].Size) + int(b.Bishops[color].Size)
+	}
+	if minors <= 1 {
+		return true
+	}
+	if b.Knights[BLACK].Size > 0 || b.Knights[WHITE].Size > 0 {
+		return false
+	}
+
+	// Only bishops are left.
+	first := OTB
+	for color := BLACK; color <= WHITE; color++ {
+		for i := uint8(0); i < b.Bishops[color].Size; i++ {
+			sqColor := b.Bishops[color].Pieces[i].SquareColor()
+			if first == OTB {
+				first = sqColor
+			} else if sqColor != first {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// MoveUndo is a compact record of the board state that cannot be
+// reconstructed from a move alone. It is returned by MakeLegalMove
+// and consumed by UnmakeMove.
+type MoveUndo struct {
+	Move        BitMove
+	Captured    Piece
+	CastleShort [2]bool
+	CastleLong  [2]bool
+	EpSquare    Square
+	DrawCounter uint16
+	Hash        uint64
+}
 
 // MakeLegalMove expects a legal move and applies it to the board.
-func (b *Board) MakeLegalMove(m BitMove) {
+// The returned MoveUndo can be passed to UnmakeMove to take the move back.
+func (b *Board) MakeLegalMove(m BitMove) MoveUndo {
 	from, to, promo := m.All()
 	oppColor := b.Player.Flip()
 	// Detect piece type and target piece
 	ptype := b.Squares[from] & PIECE_MASK
 	tpiece := b.Squares[to]
 
+	undo := MoveUndo{
+		Move:        m,
+		Captured:    tpiece,
+		CastleShort: b.CastleShort,
+		CastleLong:  b.CastleLong,
+		EpSquare:    b.EpSquare,
+		DrawCounter: b.DrawCounter,
+		Hash:        b.Hash,
+	}
+	// Remove castling rights and e.p. square from the hash. They are added back after the move.
+	b.Hash ^= b.zobristState()
+
 	// Test if it is a capture.
 	if !tpiece.IsEmpty() {
 		if tpiece.Contains(KING) {
@@ -341,10 +487,17 @@ func (b *Board) MakeLegalMove(m BitMove) {
 		}
 	} else if ptype == PAWN && to == b.EpSquare { // Is it an e.p. capture?
 		capSq := Square(int8(to) + PAWN_PUSH_DIRS[oppColor])
+		undo.Captured = b.Squares[capSq]
 		b.removePiece(capSq)
 	}
 	// Now make the actual move on the board.
-	b.Squares[to], b.Squares[from] = b.Squares[from], EMPTY
+	piece := b.Squares[from]
+	b.Hash ^= zobristPiece(piece, from)
+	if promo == NONE {
+		// Promoted pieces are hashed when they are added.
+		b.Hash ^= zobristPiece(piece, to)
+	}
+	b.Squares[to], b.Squares[from] = piece, EMPTY
 	// Remove any possible e.p. squares.
 	b.EpSquare = OTB
 
@@ -387,12 +540,14 @@ func (b *Board) MakeLegalMove(m BitMove) {
 		if shortCastle {
 			rookFrom := CASTLING_ROOK_SHORT[b.Player]
 			rookTo := CASTLING_PATH_SHORT[b.Player][0]
+			b.Hash ^= zobristPiece(ROOK|b.Player, rookFrom) ^ zobristPiece(ROOK|b.Player, rookTo)
 			b.Squares[rookTo], b.Squares[rookFrom] = ROOK|b.Player, EMPTY
 			b.Rooks[b.Player].Move(rookFrom, rookTo)
 			b.Sliders[b.Player].Move(rookFrom, rookTo)
 		} else if longCastle {
 			rookFrom := CASTLING_ROOK_LONG[b.Player]
 			rookTo := CASTLING_PATH_LONG[b.Player][0]
+			b.Hash ^= zobristPiece(ROOK|b.Player, rookFrom) ^ zobristPiece(ROOK|b.Player, rookTo)
 			b.Squares[rookTo], b.Squares[rookFrom] = ROOK|b.Player, EMPTY
 			b.Rooks[b.Player].Move(rookFrom, rookTo)
 			b.Sliders[b.Player].Move(rookFrom, rookTo)
@@ -405,13 +560,141 @@ func (b *Board) MakeLegalMove(m BitMove) {
 
 	b.Player = b.Player.Flip()
 	b.MoveNumber++
-	// TODO half draw counter
+	// Pawn moves and captures reset the half move clock.
+	if ptype == PAWN || !undo.Captured.IsEmpty() {
+		b.DrawCounter = 0
+	} else {
+		b.DrawCounter++
+	}
+
+	// Add the new castling rights and e.p. square and switch the side to move.
+	b.Hash ^= b.zobristState() ^ zobristWhite
+
+	return undo
+}
+
+// UnmakeMove takes back the move recorded in undo. It must be the last move
+// made on the board. The info board (checks and pins) is not restored. It is
+// recomputed by DetectChecksAndPins before it is needed again.
+func (b *Board) UnmakeMove(undo MoveUndo) {
+	from, to, promo := undo.Move.All()
+	b.Player = b.Player.Flip()
+	b.MoveNumber--
+	ptype := b.Squares[to] & PIECE_MASK
+
+	// Move the piece back to where it came from.
+	if promo != NONE {
+		b.removePiece(to)
+		b.addPiece(from, PAWN|b.Player)
+	} else {
+		b.Squares[from], b.Squares[to] = b.Squares[to], EMPTY
+
+		switch ptype {
+		case PAWN:
+			b.Pawns[b.Player].Move(to, from)
+		case KNIGHT:
+			b.Knights[b.Player].Move(to, from)
+		case BISHOP:
+			b.Bishops[b.Player].Move(to, from)
+			b.Sliders[b.Player].Move(to, from)
+		case ROOK:
+			b.Rooks[b.Player].Move(to, from)
+			b.Sliders[b.Player].Move(to, from)
+		case QUEEN:
+			b.Queens[b.Player].Move(to, from)
+			b.Sliders[b.Player].Move(to, from)
+		case KING:
+			b.Kings[b.Player] = from
+
+			shortCastle := (from == CASTLING_DETECT_SHORT[b.Player][0]) && (to == CASTLING_DETECT_SHORT[b.Player][1])
+			longCastle := (from == CASTLING_DETECT_LONG[b.Player][0]) && (to == CASTLING_DETECT_LONG[b.Player][1])
+			// Teleport the rook back, if the move was castling.
+			if shortCastle {
+				rookFrom := CASTLING_ROOK_SHORT[b.Player]
+				rookTo := CASTLING_PATH_SHORT[b.Player][0]
+				b.Squares[rookFrom], b.Squares[rookTo] = ROOK|b.Player, EMPTY
+				b.Rooks[b.Player].Move(rookTo, rookFrom)
+				b.Sliders[b.Player].Move(rookTo, rookFrom)
+			} else if longCastle {
+				rookFrom := CASTLING_ROOK_LONG[b.Player]
+				rookTo := CASTLING_PATH_LONG[b.Player][0]
+				b.Squares[rookFrom], b.Squares[rookTo] = ROOK|b.Player, EMPTY
+				b.Rooks[b.Player].Move(rookTo, rookFrom)
+				b.Sliders[b.Player].Move(rookTo, rookFrom)
+			}
+		default:
+			// This should not happen..
+			panic("Board.UnmakeMove: " + fmt.Sprintf("%v", undo.Move))
+		}
+	}
+
+	// Put back a captured piece.
+	if !undo.Captured.IsEmpty() {
+		capSq := to
+		if ptype == PAWN && to == undo.EpSquare {
+			// The captured pawn of an e.p. capture is not on the target square.
+			capSq = Square(int8(to) + PAWN_PUSH_DIRS[b.Player.Flip()])
+		}
+		b.addPiece(capSq, undo.Captured)
+	}
+
+	b.CastleShort = undo.CastleShort
+	b.CastleLong = undo.CastleLong
+	b.EpSquare = undo.EpSquare
+	b.DrawCounter = undo.DrawCounter
+	b.Hash = undo.Hash
+}
+
+// MakeNullMove passes the turn to the opponent without moving a piece. It is used by
+// null-move pruning and must not be made while the player to move is in check.
+// The half move clock is reset, so repetitions are not detected across a null move.
+func (b *Board) MakeNullMove() MoveUndo {
+	undo := MoveUndo{
+		Move:        BitMove(0),
+		Captured:    EMPTY,
+		CastleShort: b.CastleShort,
+		CastleLong:  b.CastleLong,
+		EpSquare:    b.EpSquare,
+		DrawCounter: b.DrawCounter,
+		Hash:        b.Hash,
+	}
+
+	b.Hash ^= b.zobristState()
+	b.EpSquare = OTB
+	b.Player = b.Player.Flip()
+	b.MoveNumber++
+	b.DrawCounter = 0
+	b.Hash ^= b.zobristState() ^ zobristWhite
+
+	return undo
+}
+
+// UnmakeNullMove takes back a null move.
+func (b *Board) UnmakeNullMove(undo MoveUndo) {
+	b.Player = b.Player.Flip()
+	b.MoveNumber--
+	b.EpSquare = undo.EpSquare
+	b.DrawCounter = undo.DrawCounter
+	b.Hash = undo.Hash
+}
+
+// InCheck returns true if the king of the player to move is attacked.
+func (b *Board) InCheck() bool {
+	return b.IsSquareAttacked(b.Kings[b.Player], OTB, b.Player)
+}
+
+// HasNonPawnMaterial returns true if the player to move has other pieces than pawns and the king.
+// Positions without such pieces are prone to zugzwang.
+func (b *Board) HasNonPawnMaterial() bool {
+	c := b.Player
+	return b.Knights[c].Size+b.Bishops[c].Size+b.Rooks[c].Size+b.Queens[c].Size > 0
 }
 
 // TODO (improvement) -> introduce movePiece function..
 
 func (b *Board) addPiece(sq Square, piece Piece) {
 	b.Squares[sq] = piece
+	b.Hash ^= zobristPiece(piece, sq)
 	ptype := piece & PIECE_MASK
 	color := piece.PieceColor()
 
@@ -441,6 +724,7 @@ func (b *Board) removePiece(sq Square) {
 	color := piece.PieceColor()
 
 	b.Squares[sq] = EMPTY
+	b.Hash ^= zobristPiece(piece, sq)
 	switch ptype {
 	case PAWN:
 		b.Pawns[color].Remove(sq)
@@ -727,12 +1011,32 @@ func (b *Board) DetectSliderChecksAndPins(color Color, pmarker *Info, ccount int
 	return checkCounter
 }
 
+// GenerateAllLegalMoves generates all legal moves for the player to move
+// and stores them in the given MoveList.
 func (b *Board) GenerateAllLegalMoves(mlist *MoveList) {
+	b.GenerateLegalMoves(mlist, GEN_ALL)
+}
+
+// GenerateCaptures generates all legal captures and promotions for the player to move
+// and stores them in the given MoveList.
+func (b *Board) GenerateCaptures(mlist *MoveList) {
+	b.GenerateLegalMoves(mlist, GEN_CAPTURES)
+}
+
+// GenerateQuiets generates all legal moves for the player to move that neither
+// capture nor promote and stores them in the given MoveList.
+func (b *Board) GenerateQuiets(mlist *MoveList) {
+	b.GenerateLegalMoves(mlist, GEN_QUIETS)
+}
+
+// GenerateLegalMoves generates the legal moves selected by gen for the player to move
+// and stores them in the given MoveList.
+func (b *Board) GenerateLegalMoves(mlist *MoveList, gen GenType) {
 	// Detect checks and pins.
 	b.DetectChecksAndPins(b.Player)
 
 	// Always generate king moves.
-	b.GenerateKingMoves(mlist, b.Player)
+	b.GenerateKingMoves(mlist, b.Player, gen)
 
 	// If there is a double check skip generating other moves.
 	if b.CheckInfo == CHECK_DOUBLE_CHECK {
@@ -740,16 +1044,16 @@ func (b *Board) GenerateAllLegalMoves(mlist *MoveList) {
 	} // Simple checks are handled by the following move generator functions.
 
 	// Generate the rest of the legal moves.
-	b.GenerateKnightMoves(mlist, b.Player)
-	b.GenerateQueenMoves(mlist, b.Player)
-	b.GenerateBishopMoves(mlist, b.Player)
-	b.GenerateRookMoves(mlist, b.Player)
-	b.GeneratePawnMoves(mlist, b.Player)
+	b.GenerateKnightMoves(mlist, b.Player, gen)
+	b.GenerateQueenMoves(mlist, b.Player, gen)
+	b.GenerateBishopMoves(mlist, b.Player, gen)
+	b.GenerateRookMoves(mlist, b.Player, gen)
+	b.GeneratePawnMoves(mlist, b.Player, gen)
 }
 
-// GeneratePawnMoves generates all legal pawn moves for the given color
+// GeneratePawnMoves generates the legal pawn moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
+func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color, gen GenType) {
 	// NOTE: Pawn moves can be very complicated and have strange effects on the board (en passent, promotion..).
 	// Because of this all pawn moves are tested for legality by 'fake-play'. This could be optimized by
 	// testing the 'easy' ones differently (TODO).
@@ -763,7 +1067,7 @@ func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
 	// Possible en passent captures are detected backwards
 	// so we do not need to add another conditional to the
 	// capture loop below.
-	if b.EpSquare != OTB {
+	if b.EpSquare != OTB && gen&GEN_CAPTURES != 0 {
 		to = b.EpSquare
 		// We use the 'wrong' color to find e.p. captures
 		// by searching in the opposite direction.
@@ -788,7 +1092,7 @@ func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
 
 		// a. Captures
 		//		for _, capdir := range PAWN_CAPTURE_DIRS[color] {
-		for d := 0; d < 2; d++ {
+		for d := 0; d < 2 && gen&GEN_CAPTURES != 0; d++ {
 			capdir := PAWN_CAPTURE_DIRS[color][d]
 			to = Square(int8(from) + capdir)
 			// If the target square is on board and has the opponent's color
@@ -821,6 +1125,10 @@ func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
 		to = Square(int8(from) + PAWN_PUSH_DIRS[color])
 		if b.Squares[to].IsEmpty() {
 			if to.IsPawnPromoting(color) {
+				if gen&GEN_CAPTURES == 0 {
+					// Promotions are generated with the captures.
+					continue
+				}
 				// If one type of promotion is legal, all are.
 				legal = b.tryPawnMoveLegality(from, to, to, EMPTY, color)
 				if legal {
@@ -830,7 +1138,7 @@ func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
 					}
 				}
 
-			} else {
+			} else if gen&GEN_QUIETS != 0 {
 				move, legal = b.newPawnMoveIfLegal(color, from, to, piece, EMPTY, EMPTY, EP_TYPE_NONE)
 				if legal {
 					mlist.Put(move)
@@ -838,7 +1146,7 @@ func (b *Board) GeneratePawnMoves(mlist *MoveList, color Color) {
 			}
 
 			// c. Double push by advancing one more time, if the pawn was at base rank.
-			if from.IsPawnBaseRank(color) {
+			if from.IsPawnBaseRank(color) && gen&GEN_QUIETS != 0 {
 				to = Square(int8(to) + PAWN_PUSH_DIRS[color])
 				if b.Squares[to].IsEmpty() {
 					move, legal = b.newPawnMoveIfLegal(color, from, to, piece, EMPTY, EMPTY, EP_TYPE_CREATE)
@@ -909,9 +1217,9 @@ func (b *Board) tryPawnMoveLegality(from, to, capSq Square, capPiece Piece, colo
 	return legal
 }
 
-// GenerateKnightMoves generates all legal knight moves for the given color
+// GenerateKnightMoves generates the legal knight moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GenerateKnightMoves(mlist *MoveList, color Color) {
+func (b *Board) GenerateKnightMoves(mlist *MoveList, color Color, gen GenType) {
 	from, to := OTB, OTB
 	tpiece := EMPTY
 	var move BitMove
@@ -937,7 +1245,7 @@ func (b *Board) GenerateKnightMoves(mlist *MoveList, color Color) {
 				if isCheck && !b.Squares[to.ToInfoIndex()].IsSet(INFO_MASK_CHECK) {
 					// If there is a check but the move's target does not change that fact -> impossible move.
 					continue
-				} else if !tpiece.HasColor(color) {
+				} else if !tpiece.HasColor(color) && gen.selects(tpiece) {
 					// Add a normal or a capture move.
 					move = NewBitMove(from, to, NONE)
 					mlist.Put(move)
@@ -947,9 +1255,9 @@ func (b *Board) GenerateKnightMoves(mlist *MoveList, color Color) {
 	}
 }
 
-// GenerateKingMoves generates all legal king moves for the given color
+// GenerateKingMoves generates the legal king moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GenerateKingMoves(mlist *MoveList, color Color) {
+func (b *Board) GenerateKingMoves(mlist *MoveList, color Color, gen GenType) {
 	from, to := b.Kings[color], OTB
 	tpiece := EMPTY
 	var move BitMove
@@ -971,7 +1279,7 @@ func (b *Board) GenerateKingMoves(mlist *MoveList, color Color) {
 			}
 			targets[i] = true
 			if !b.Squares[to.ToInfoIndex()].IsSet(INFO_MASK_FORBIDDEN_ESCAPE) {
-				if !tpiece.HasColor(color) {
+				if gen.selects(tpiece) {
 					// Add a normal or capture move.
 					move = NewBitMove(from, to, NONE)
 					mlist.Put(move)
@@ -980,8 +1288,8 @@ func (b *Board) GenerateKingMoves(mlist *MoveList, color Color) {
 		}
 	}
 
-	if b.CheckInfo != CHECK_NONE {
-		// Cannot castle when in check.
+	if b.CheckInfo != CHECK_NONE || gen&GEN_QUIETS == 0 {
+		// Cannot castle when in check. Castling is a quiet move.
 		return
 	}
 
@@ -1026,10 +1334,10 @@ func (b *Board) GenerateKingMoves(mlist *MoveList, color Color) {
 	}
 }
 
-// GenerateBishopMoves generates all legal bishop moves for the given color
+// GenerateBishopMoves generates the legal bishop moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GenerateBishopMoves(mlist *MoveList, color Color) {
-	b.GenerateSlidingMoves(mlist, color, BISHOP, DIAGONAL_DIRS, &b.Bishops[color])
+func (b *Board) GenerateBishopMoves(mlist *MoveList, color Color, gen GenType) {
+	b.GenerateSlidingMoves(mlist, color, BISHOP, DIAGONAL_DIRS, &b.Bishops[color], gen)
 	//	from := OTB
 	//	isCheck := b.CheckInfo.OnBoard()
 	//	oppColor := color.Flip()
@@ -1046,10 +1354,10 @@ func (b *Board) GenerateBishopMoves(mlist *MoveList, color Color) {
 	//	}
 }
 
-// GenerateRookMoves generates all legal rook moves for the given color
+// GenerateRookMoves generates the legal rook moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GenerateRookMoves(mlist *MoveList, color Color) {
-	b.GenerateSlidingMoves(mlist, color, ROOK, ORTHOGONAL_DIRS, &b.Rooks[color])
+func (b *Board) GenerateRookMoves(mlist *MoveList, color Color, gen GenType) {
+	b.GenerateSlidingMoves(mlist, color, ROOK, ORTHOGONAL_DIRS, &b.Rooks[color], gen)
 	//	from := OTB
 	//	isCheck := b.CheckInfo.OnBoard()
 	//	oppColor := color.Flip()
@@ -1066,11 +1374,11 @@ func (b *Board) GenerateRookMoves(mlist *MoveList, color Color) {
 	//	}
 }
 
-// GenerateQueenMoves generates all legal rook moves for the given color
+// GenerateQueenMoves generates the legal rook moves selected by gen for the given color
 // and stores them in the given MoveList.
-func (b *Board) GenerateQueenMoves(mlist *MoveList, color Color) {
-	b.GenerateSlidingMoves(mlist, color, QUEEN, ORTHOGONAL_DIRS, &b.Queens[color])
-	b.GenerateSlidingMoves(mlist, color, QUEEN, DIAGONAL_DIRS, &b.Queens[color])
+func (b *Board) GenerateQueenMoves(mlist *MoveList, color Color, gen GenType) {
+	b.GenerateSlidingMoves(mlist, color, QUEEN, ORTHOGONAL_DIRS, &b.Queens[color], gen)
+	b.GenerateSlidingMoves(mlist, color, QUEEN, DIAGONAL_DIRS, &b.Queens[color], gen)
 	//	from := OTB
 	//	isCheck := b.CheckInfo.OnBoard()
 	//	oppColor := color.Flip()
@@ -1129,10 +1437,10 @@ func (b *Board) GenerateQueenMoves(mlist *MoveList, color Color) {
 //	}
 //}
 
-// GenerateSlidingMoves generates all legal sliding moves for the given color
+// GenerateSlidingMoves generates the legal sliding moves selected by gen for the given color
 // and stores them in the given MoveList. This can be diagonal or orthogonal moves.
 // This function is used to create all bishop, rook and queen moves.
-func (b *Board) GenerateSlidingMoves(mlist *MoveList, color Color, ptype Piece, dirs [4]int8, plist *PieceList) {
+func (b *Board) GenerateSlidingMoves(mlist *MoveList, color Color, ptype Piece, dirs [4]int8, plist *PieceList, gen GenType) {
 	from, to := OTB, OTB
 	tpiece := EMPTY
 	var move BitMove
@@ -1175,13 +1483,17 @@ func (b *Board) GenerateSlidingMoves(mlist *MoveList, color Color, ptype Piece,
 					} else {
 						if tpiece.IsEmpty() {
 							// Add a normal move.
-							move = NewBitMove(from, to, NONE)
-							mlist.Put(move)
+							if gen&GEN_QUIETS != 0 {
+								move = NewBitMove(from, to, NONE)
+								mlist.Put(move)
+							}
 							// And continue in current direction.
 						} else if tpiece.HasColor(oppColor) {
 							// Add a capture move.
-							move = NewBitMove(from, to, NONE)
-							mlist.Put(move)
+							if gen&GEN_CAPTURES != 0 {
+								move = NewBitMove(from, to, NONE)
+								mlist.Put(move)
+							}
 							// And go to next direction.
 							break
 						}
@@ -1195,7 +1507,6 @@ func (b *Board) GenerateSlidingMoves(mlist *MoveList, color Color, ptype Piece,
 
 func (b *Board) Perft(depth int) uint64 {
 	mlist := MoveList{}
-	cpy := *b
 	nodes := uint64(0)
 
 	if depth <= 0 {
@@ -1209,11 +1520,11 @@ func (b *Board) Perft(depth int) uint64 {
 
 	for i := uint32(0); i < mlist.Size; i++ {
 		move := &mlist.Moves[i]
-		b.MakeLegalMove(*move)
+		undo := b.MakeLegalMove(*move)
 		n := b.Perft(depth - 1)
 		nodes += n
 
-		*b = cpy //TODO --> better move undo
+		b.UnmakeMove(undo)
 	}
 
 	return nodes
@@ -1221,18 +1532,17 @@ func (b *Board) Perft(depth int) uint64 {
 
 func (b *Board) PerftDivide(depth int) map[string]uint64 {
 	mlist := MoveList{}
-	cpy := *b
 	results := map[string]uint64{}
 
 	b.GenerateAllLegalMoves(&mlist)
 
 	for i := uint32(0); i < mlist.Size; i++ {
 		move := &mlist.Moves[i]
-		b.MakeLegalMove(*move)
+		undo := b.MakeLegalMove(*move)
 		n := b.Perft(depth - 1)
 		results[move.MiniNotation()] += n
 
-		*b = cpy //TODO --> better move undo
+		b.UnmakeMove(undo)
 	}
 
 	return results
@@ -1,6 +1,7 @@
 package chesskimo
 
 import (
+	"fmt"
 	"testing"
 	"time"
 )
@@ -162,7 +163,7 @@ func TestGenerateKingMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GenerateKingMoves(&mlist, board.Player)
+		board.GenerateKingMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s expected move list: %s\n but got: %s\n", &board, results[i], &mlist)
@@ -186,7 +187,7 @@ func TestGenerateQueenMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GenerateQueenMoves(&mlist, board.Player)
+		board.GenerateQueenMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s expected move list: %s\n but got: %s\n", &board, results[i], &mlist)
@@ -210,7 +211,7 @@ func TestGenerateRookMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GenerateRookMoves(&mlist, board.Player)
+		board.GenerateRookMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s expected move list: %s\n but got: %s\n", &board, results[i], &mlist)
@@ -234,7 +235,7 @@ func TestGenerateBishopMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GenerateBishopMoves(&mlist, board.Player)
+		board.GenerateBishopMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s expected move list: %s\n but got: %s\n", &board, results[i], &mlist)
@@ -260,7 +261,7 @@ func TestGenerateKnightMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GenerateKnightMoves(&mlist, board.Player)
+		board.GenerateKnightMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s %s expected move list: %s\n but got: %s\n", &board, board.InfoBoardString(), results[i], &mlist)
@@ -292,10 +293,198 @@ func TestGeneratePawnMoves(t *testing.T) {
 	for i, fen := range fens {
 		board.SetFEN(fen)
 		mlist.Clear()
-		board.GeneratePawnMoves(&mlist, board.Player)
+		board.GeneratePawnMoves(&mlist, board.Player, GEN_ALL)
 		strmoves := mlist.String()
 		if strmoves != results[i] {
 			t.Fatalf("Position\n %s \n test %d\n expected move list: %s\n but got           : %s\n", &board, i, results[i], &mlist)
 		}
 	}
 }
+
+// sameBoardState compares two boards by their playing squares, state variables
+// and the content (but not the order) of their piece lists.
+func sameBoardState(b1, b2 *Board) bool {
+	for _, sq := range Lookup0x88 {
+		if b1.Squares[sq] != b2.Squares[sq] {
+			return false
+		}
+	}
+	if b1.CastleShort != b2.CastleShort || b1.CastleLong != b2.CastleLong ||
+		b1.MoveNumber != b2.MoveNumber || b1.DrawCounter != b2.DrawCounter ||
+		b1.EpSquare != b2.EpSquare || b1.Player != b2.Player || b1.Kings != b2.Kings {
+		return false
+	}
+
+	sameList := func(l1, l2 *PieceList) bool {
+		if l1.Size != l2.Size {
+			return false
+		}
+		found := 0
+		for i := uint8(0); i < l1.Size; i++ {
+			for j := uint8(0); j < l2.Size; j++ {
+				if l1.Pieces[i] == l2.Pieces[j] {
+					found++
+				}
+			}
+		}
+		return found == int(l1.Size)
+	}
+	for color := BLACK; color <= WHITE; color++ {
+		if !sameList(&b1.Sliders[color], &b2.Sliders[color]) || !sameList(&b1.Queens[color], &b2.Queens[color]) ||
+			!sameList(&b1.Rooks[color], &b2.Rooks[color]) || !sameList(&b1.Bishops[color], &b2.Bishops[color]) ||
+			!sameList(&b1.Knights[color], &b2.Knights[color]) || !sameList(&b1.Pawns[color], &b2.Pawns[color]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func TestUnmakeMove(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+		"rnbqkb1r/pp2pppp/5n2/2ppP3/3P4/8/PPP2PPP/RNBQKBNR w KQkq d6 0 4",
+	}
+
+	board := NewBoard()
+	mlist := MoveList{}
+
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		mlist.Clear()
+		board.GenerateAllLegalMoves(&mlist)
+		cpy := board
+
+		for i := uint32(0); i < mlist.Size; i++ {
+			undo := board.MakeLegalMove(mlist.Moves[i])
+			board.UnmakeMove(undo)
+			if !sameBoardState(&board, &cpy) {
+				t.Fatalf("Unmaking move %s in FEN %s results in\n%s\nbut should be\n%s\n", mlist.Moves[i].MiniNotation(), fen, &board, &cpy)
+			}
+		}
+	}
+}
+
+// TestGenerateCapturesAndQuiets tests that captures and quiet moves together are
+// exactly all legal moves in every position of a perft tree of the benchmark FENs.
+func TestGenerateCapturesAndQuiets(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+	}
+
+	board := NewBoard()
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		if err := splitPerft(&board, 3); err != "" {
+			t.Fatalf("FEN %s: %s\n", fen, err)
+		}
+	}
+}
+
+// splitPerft walks the perft tree up to depth and compares the generators in every node.
+// It returns a description of the first mismatch or "".
+func splitPerft(board *Board, depth int) string {
+	all, captures, quiets := MoveList{}, MoveList{}, MoveList{}
+	board.GenerateAllLegalMoves(&all)
+	board.GenerateCaptures(&captures)
+	board.GenerateQuiets(&quiets)
+
+	found := map[BitMove]bool{}
+	for i := uint32(0); i < captures.Size; i++ {
+		m := captures.Moves[i]
+		if board.Squares[m.To()].IsEmpty() && m.PromotedPiece() == NONE &&
+			!(m.To() == board.EpSquare && board.Squares[m.From()] == PAWN|board.Player) {
+			return "quiet move " + m.MiniNotation() + " generated as capture in " + board.FEN()
+		}
+		found[m] = true
+	}
+	for i := uint32(0); i < quiets.Size; i++ {
+		m := quiets.Moves[i]
+		if !board.Squares[m.To()].IsEmpty() || m.PromotedPiece() != NONE {
+			return "capture " + m.MiniNotation() + " generated as quiet move in " + board.FEN()
+		}
+		found[m] = true
+	}
+	if captures.Size+quiets.Size != all.Size || len(found) != int(all.Size) {
+		return fmt.Sprintf("%d captures and %d quiet moves but %d legal moves in %s", captures.Size, quiets.Size, all.Size, board.FEN())
+	}
+
+	if depth <= 1 {
+		return ""
+	}
+	for i := uint32(0); i < all.Size; i++ {
+		if !found[all.Moves[i]] {
+			return "legal move " + all.Moves[i].MiniNotation() + " missing in " + board.FEN()
+		}
+		undo := board.MakeLegalMove(all.Moves[i])
+		err := splitPerft(board, depth-1)
+		board.UnmakeMove(undo)
+		if err != "" {
+			return err
+		}
+	}
+	return ""
+}
+
+// TestNullMove tests that a null move only passes the turn and can be taken back.
+func TestNullMove(t *testing.T) {
+	board := NewBoard()
+	if err := board.SetFEN("rnbqkb1r/pp2pppp/5n2/2ppP3/3P4/8/PPP2PPP/RNBQKBNR w KQkq d6 0 4"); err != nil {
+		t.Fatalf(err.Error())
+	}
+	cpy := board
+
+	undo := board.MakeNullMove()
+	if board.Player != BLACK || board.EpSquare != OTB {
+		t.Fatalf("Expected black to move without e.p. square after a null move\n")
+	}
+	if err := board.ValidateHash(); err != nil {
+		t.Fatalf("Null move: %s\n", err)
+	}
+	board.UnmakeNullMove(undo)
+	if !sameBoardState(&board, &cpy) {
+		t.Fatalf("Unmaking the null move results in\n%s\nbut should be\n%s\n", &board, &cpy)
+	}
+}
+
+// TestResult tests the detection of finished games.
+func TestResult(t *testing.T) {
+	type set struct {
+		FEN    string
+		State  State
+		Reason ResultReason
+	}
+	testsets := []set{
+		{"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", GAMESTATE_ONGOING, REASON_NONE},
+		{"rnb1kbnr/pppp1ppp/8/4p3/6Pq/5P2/PPPPP2P/RNBQKBNR w KQkq - 1 3", GAMESTATE_BLACK_WIN, REASON_CHECKMATE},
+		{"7k/5Q2/6K1/8/8/8/8/8 b - - 0 1", GAMESTATE_DRAW, REASON_STALEMATE},
+		{"R5k1/5ppp/8/8/8/8/8/6K1 b - - 120 80", GAMESTATE_WHITE_WIN, REASON_CHECKMATE},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 99 80", GAMESTATE_ONGOING, REASON_NONE},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 100 80", GAMESTATE_DRAW, REASON_FIFTY_MOVES},
+		{"4k3/8/8/8/8/8/4P3/4K3 w - - 150 80", GAMESTATE_DRAW, REASON_SEVENTY_FIVE_MOVES},
+		{"4k3/8/8/8/8/8/8/4K3 w - - 0 1", GAMESTATE_DRAW, REASON_INSUFFICIENT_MATERIAL},
+		{"4k3/8/8/8/8/8/8/4KN2 w - - 0 1", GAMESTATE_DRAW, REASON_INSUFFICIENT_MATERIAL},
+		{"4kb2/8/8/8/8/8/8/2B1K3 w - - 0 1", GAMESTATE_DRAW, REASON_INSUFFICIENT_MATERIAL},
+		{"4k1b1/8/8/8/8/8/8/2B1K3 w - - 0 1", GAMESTATE_ONGOING, REASON_NONE},
+		{"4k3/8/8/8/8/8/8/3NKN2 w - - 0 1", GAMESTATE_ONGOING, REASON_NONE},
+	}
+
+	for i, ts := range testsets {
+		board := NewBoard()
+		if err := board.SetFEN(ts.FEN); err != nil {
+			t.Fatalf("Test %d: invalid FEN: %s\n", i, err)
+		}
+		state, reason := board.Result(nil)
+		if state != ts.State || reason != ts.Reason {
+			t.Fatalf("Test %d: expected %d (%s) but got %d (%s)\n", i, ts.State, ts.Reason, state, reason)
+		}
+	}
+}
@@ -15,8 +15,8 @@ func main() {
 	fmt.Println("Chesskimo", version)
 
 	uci := &chesskimo.UCI{}
-	engine := chesskimo.NewEngine("Chesskimo "+version+" 2022", "David Linus Briemann", uci, chesskimo.SimpleMCSearch)
-	// engine := chesskimo.NewEngine("Chesskimo "+version+" 2022", "David Linus Briemann", uci, chesskimo.AlphaBetaSearch)
+	// engine := chesskimo.NewEngine("Chesskimo "+version+" 2022", "David Linus Briemann", uci, chesskimo.MONTE_CARLO_SEARCH)
+	engine := chesskimo.NewEngine("Chesskimo "+version+" 2022", "David Linus Briemann", uci, chesskimo.ALPHA_BETA_SEARCH)
 
 	// Input/output runs until exit.
 	engine.Run()
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/dbriemann/chesskimo"
+)
+
+var version = "undefined"
+
+var (
+	moveTime = flag.Int("time", 1000, "search time per position in milliseconds (0 = end by -depth; without -depth or with -search=mc after the default move time)")
+	depth    = flag.Int("depth", 0, "search depth per position (0 = no limit)")
+	search   = flag.String("search", "ab", "search function: 'ab' (alpha-beta) or 'mc' (monte carlo)")
+	hash     = flag.Int("hash", chesskimo.TT_DEFAULT_MB, "transposition table size in MB")
+)
+
+func main() {
+	fmt.Println("Version", version)
+
+	flag.Usage = func() {
+		fmt.Fprintf(os.Stderr, "Usage: %s [flags] suite.epd\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	namedSearch := chesskimo.ALPHA_BETA_SEARCH
+	switch *search {
+	case "ab":
+	case "mc":
+		namedSearch = chesskimo.MONTE_CARLO_SEARCH
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	f, err := os.Open(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	defer f.Close()
+
+	engine := chesskimo.NewEngine("Chesskimo "+version, "David Linus Briemann", nil, namedSearch)
+	if err := engine.SetOption("Hash", strconv.Itoa(*hash)); err != nil {
+		fmt.Fprintln(os.Stderr, "hash:", err)
+		os.Exit(2)
+	}
+	ss := chesskimo.SearchSettings{
+		MaxDepth: *depth,
+		MoveTime: time.Duration(*moveTime) * time.Millisecond,
+	}
+
+	total, solved := 0, 0
+	fmt.Println("id,result,move,expected,depth,score,seconds")
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		record := strings.TrimSpace(scanner.Text())
+		if record == "" || strings.HasPrefix(record, "#") {
+			continue
+		}
+		epd, err := chesskimo.ParseEPD(record)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "line %d: %s\n", line, err)
+			continue
+		}
+		id := epd.ID()
+		if id == "" {
+			id = "line " + strconv.Itoa(line)
+		}
+
+		board := epd.Board()
+		engine.NewGame()
+		if err := engine.SetPosition(board.FEN(), nil); err != nil {
+			fmt.Fprintf(os.Stderr, "%s: %s\n", id, err)
+			continue
+		}
+		settings := ss
+		start := time.Now()
+		sr := engine.Search(&settings)
+		elapsed := time.Since(start)
+
+		ok, expected := isSolved(&epd, &board, sr)
+		if expected == "" {
+			// Nothing to solve in this position.
+			continue
+		}
+		total++
+		result := "failed"
+		if ok {
+			solved++
+			result = "solved"
+		}
+		move := "-"
+		if sr.Move != chesskimo.BitMove(0) {
+			move = board.MoveToSAN(sr.Move)
+		}
+		fmt.Printf("\"%s\",%s,%s,\"%s\",%d,%d,%f\n", id, result, move, expected, sr.Depth, sr.Score, elapsed.Seconds())
+	}
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	fmt.Printf("**Solved: %d/%d**\n", solved, total)
+}
+
+// isSolved tests the search result against the best moves (bm), the moves to
+// avoid (am) and the direct mate (dm) of the record. It also returns the
+// expectation as text, which is empty if the record has none of them.
+func isSolved(epd *chesskimo.EPD, board *chesskimo.Board, sr chesskimo.SearchResult) (bool, string) {
+	expected := []string{}
+	ok := true
+
+	if bm := epd.Operands("bm"); len(bm) > 0 {
+		expected = append(expected, "bm "+strings.Join(bm, " "))
+		ok = ok && containsMove(board, bm, sr.Move)
+	}
+	if am := epd.Operands("am"); len(am) > 0 {
+		expected = append(expected, "am "+strings.Join(am, " "))
+		ok = ok && !containsMove(board, am, sr.Move)
+	}
+	if dm, found := epd.IntOperand("dm"); found {
+		expected = append(expected, "dm "+strconv.Itoa(dm))
+		// A mate in n moves is at most 2n-1 plies away.
+		ok = ok && sr.Score >= chesskimo.MATE_SCORE-(2*dm-1)
+	}
+
+	return ok, strings.Join(expected, "; ")
+}
+
+// containsMove returns true if one of the SAN moves is m.
+func containsMove(board *chesskimo.Board, sans []string, m chesskimo.BitMove) bool {
+	for _, san := range sans {
+		if bm, err := board.ParseSAN(san); err == nil && bm == m {
+			return true
+		}
+	}
+	return false
+}
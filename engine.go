@@ -2,9 +2,14 @@ package chesskimo
 
 import (
 	"errors"
+	"fmt"
+	"io/ioutil"
 	"log"
 	"os"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 const (
@@ -23,96 +28,344 @@ type Engine struct {
 	author string
 	// protocol defines how the engine communicates with the frontend.
 	protocol Communicator
-	// atomicState defines the current state of the engine
-	//	atomicState uint32
-	//	waitgroup   sync.WaitGroup
+	// stop is set to a non-zero value (atomically) to interrupt a running search.
+	stop uint32
+	// debug is non-zero (atomically) if the frontend wants additional information.
+	debug uint32
+	// searching waits for the goroutine of a running search.
+	searching sync.WaitGroup
 
-	board  Board
-	search SearchFun
+	board Board
+	// history contains the keys of all positions of the game before the current board.
+	history MoveHistory
+	search  SearchFun
+	// tt is the transposition table shared by all searches of this engine.
+	tt *TranspositionTable
+	// multiPV is the number of principal variations the search reports.
+	multiPV int
+	// selectivity switches the pruning techniques of the alpha-beta search.
+	selectivity Selectivity
 
-	logger *log.Logger
+	// options are the settings the frontend may change.
+	options []*Option
+
+	// gameStart and gameMoves record the current game, evals contains the results of
+	// the searches by position key. The game is written to pgnFile (if set) when a new game starts.
+	gameStart Board
+	gameMoves []BitMove
+	evals     map[uint64]searchEval
+	pgnFile   string
+
+	logger  *log.Logger
+	logFile *os.File
 }
 
-func NewEngine(name, author string, protocol Communicator, searchFun SearchFun) *Engine {
+// NewEngine creates an engine that uses 'search' until the Search option is changed.
+func NewEngine(name, author string, protocol Communicator, search NamedSearch) *Engine {
 	e := &Engine{
 		name:     name,
 		author:   author,
 		protocol: protocol,
 		board:    NewBoard(),
-		search:   searchFun,
+		search:   search.Fun,
+		tt:       NewTranspositionTable(TT_DEFAULT_MB),
+		multiPV:  1,
+		options:  newOptions(search),
+		evals:    map[uint64]searchEval{},
+		// All pruning techniques are enabled by default, like the options.
+		selectivity: FULL_SELECTIVITY,
+		// Discard log output until Run opens the log file.
+		logger: log.New(ioutil.Discard, "", 0),
 	}
 
+	e.gameStart = e.board
+
 	return e
 }
 
+// searchEval is the result of a search for the PGN output.
+type searchEval struct {
+	move    BitMove
+	comment string
+}
+
 func (e *Engine) Run() {
-	f, err := os.Create("chesskimo.log")
-	if err != nil {
+	if err := e.setLogFile(e.Option("LogFile").Value()); err != nil {
 		panic("Cannot create log file.")
 	}
-	defer f.Close()
-	e.logger = log.New(f, "", log.LstdFlags|log.Lshortfile)
+	defer e.setLogFile("")
 
 	e.protocol.RunInputOutputLoop(e)
 }
 
+// setLogFile closes the current log file and writes the log to a new file at path.
+// An empty path discards the log output.
+func (e *Engine) setLogFile(path string) error {
+	if e.logFile != nil {
+		e.logFile.Close()
+		e.logFile = nil
+	}
+	e.logger = log.New(ioutil.Discard, "", 0)
+	if path == "" {
+		return nil
+	}
+
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	e.logFile = f
+	e.logger = log.New(f, "", log.LstdFlags|log.Lshortfile)
+	return nil
+}
+
+// Options returns all options of the engine in the order they were declared.
+func (e *Engine) Options() []*Option {
+	return e.options
+}
+
+// Option returns the option with the given name or nil if it does not exist.
+// Option names are case insensitive.
+func (e *Engine) Option(name string) *Option {
+	for _, o := range e.options {
+		if strings.EqualFold(o.Name, name) {
+			return o
+		}
+	}
+	return nil
+}
+
+// SetOption stops a running search, validates value and applies it to the option with the given name.
+// The option keeps its old value if an error is returned.
+func (e *Engine) SetOption(name, value string) error {
+	o := e.Option(name)
+	if o == nil {
+		return ErrUnknownOption
+	}
+	value, err := o.normalize(value)
+	if err != nil {
+		return err
+	}
+
+	e.StopSearch()
+	if o.apply != nil {
+		if err := o.apply(e, value); err != nil {
+			return err
+		}
+	}
+	o.value = value
+	return nil
+}
+
+// NewGame stops a running search and resets the board and the transposition table.
 func (e *Engine) NewGame() {
+	e.StopSearch()
+	e.writeGame()
 	e.board = NewBoard()
+	e.history.Reset()
+	e.gameStart, e.gameMoves = e.board, nil
+	e.evals = map[uint64]searchEval{}
+	e.tt.Clear()
 }
 
-// Quit shuts everything down gracefully and returns.
-func (e *Engine) Quit() {
-	//	atomic.StoreUint32(&e.atomicState, ENGINE_STATE_QUIT)
-	// TODO wait for search..
+// Search runs the search function on the current position and returns its result.
+// It blocks until the search has finished.
+func (e *Engine) Search(ss *SearchSettings) SearchResult {
+	e.StopSearch()
+	atomic.StoreUint32(&e.stop, 0)
+	start := time.Now()
+	sr := e.search(e, ss, &e.stop)
+	e.recordEval(sr, time.Since(start))
+	return sr
 }
 
-func (e *Engine) GetLegalMoves() MoveList {
-	ml := MoveList{}
-	e.board.GenerateAllLegalMoves(&ml)
-	return ml
+// StartSearch runs the search function on the current position in its own goroutine
+// and returns immediately. When the search has finished, done is called with the
+// result from that goroutine. An infinite search only finishes after StopSearch.
+func (e *Engine) StartSearch(ss SearchSettings, done func(SearchResult)) {
+	e.StopSearch()
+	atomic.StoreUint32(&e.stop, 0)
+
+	e.searching.Add(1)
+	go func() {
+		defer e.searching.Done()
+		start := time.Now()
+		sr := e.search(e, &ss, &e.stop)
+		e.recordEval(sr, time.Since(start))
+		// The result of an infinite search must not be reported before it is stopped.
+		for ss.Infinite && atomic.LoadUint32(&e.stop) == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		done(sr)
+	}()
 }
 
-func (e *Engine) MakeMove(move string) error {
-	if len(move) >= 4 {
-		promo := NONE
+// StopSearch interrupts a running search and waits until it has finished
+// and reported its result. It returns immediately if no search is running.
+func (e *Engine) StopSearch() {
+	atomic.StoreUint32(&e.stop, 1)
+	e.searching.Wait()
+}
 
-		from, err := parseFENSquare(move[0:2])
-		if err != nil {
-			return ErrInvalidMoveNotation
-		}
+// newTimeManager creates the time manager for a search of the current position.
+func (e *Engine) newTimeManager(ss *SearchSettings) TimeManager {
+	return NewTimeManager(ss, e.board.Player)
+}
+
+// SetDebug switches the debug mode. In debug mode the searches send statistics
+// for the developer to the frontend.
+func (e *Engine) SetDebug(on bool) {
+	value := uint32(0)
+	if on {
+		value = 1
+	}
+	atomic.StoreUint32(&e.debug, value)
+}
 
-		to, err := parseFENSquare(move[2:4])
-		if err != nil {
-			return ErrInvalidMoveNotation
+// debugging returns true in debug mode.
+func (e *Engine) debugging() bool {
+	return atomic.LoadUint32(&e.debug) != 0
+}
+
+// IsReady blocks until the engine is at a safe point to receive commands.
+// A search that was told to stop is still reporting its result and is waited for.
+// A search that is still running does not block, because every command that
+// changes the engine state stops it first.
+func (e *Engine) IsReady() {
+	if atomic.LoadUint32(&e.stop) != 0 {
+		e.searching.Wait()
+	}
+}
+
+// sendInfo reports search progress to the frontend, if there is one.
+func (e *Engine) sendInfo(info SearchInfo) {
+	if e.protocol != nil {
+		e.protocol.SendInfo(info)
+	}
+}
+
+// recordEval remembers the result of a search of the current position for the PGN output.
+func (e *Engine) recordEval(sr SearchResult, elapsed time.Duration) {
+	if sr.Move != BitMove(0) {
+		e.evals[e.board.Hash] = searchEval{sr.Move, PGNEvalComment(sr.Score, sr.Depth, elapsed)}
+	}
+}
+
+// writeGame appends the current game to the PGN file, if one is set and the game has moves.
+// Moves that the engine found in its searches get the evaluation as comment.
+func (e *Engine) writeGame() {
+	if e.pgnFile == "" {
+		return
+	}
+
+	game := NewPGNGame(e.gameStart)
+	game.SetTag("Date", time.Now().Format("2006.01.02"))
+	board := e.gameStart
+	played := [2]bool{}
+	for _, m := range e.gameMoves {
+		comment := ""
+		if ev, ok := e.evals[board.Hash]; ok && ev.move == m {
+			comment = ev.comment
+			played[board.Player] = true
+		}
+		game.AddMove(m, comment)
+		board.MakeLegalMove(m)
+	}
+	// The frontend does not send the position after the last move of the engine.
+	// It is added if it ended the game.
+	if ev, ok := e.evals[board.Hash]; ok {
+		after := board
+		after.MakeLegalMove(ev.move)
+		if state, _ := after.Result(nil); state != GAMESTATE_ONGOING {
+			game.AddMove(ev.move, ev.comment)
+			played[board.Player] = true
+			board = after
 		}
+	}
+	if len(game.Moves) == 0 {
+		return
+	}
+
+	if played[WHITE] {
+		game.SetTag("White", e.name)
+	}
+	if played[BLACK] {
+		game.SetTag("Black", e.name)
+	}
+	state, _ := board.Result(nil)
+	game.Result = PGNResult(state)
 
-		if len(move) == 5 {
-			switch strings.ToLower(move[4:5]) {
-			case "q":
-				promo = QUEEN
-			case "r":
-				promo = ROOK
-			case "b":
-				promo = BISHOP
-			case "n":
-				promo = KNIGHT
-			default:
-				// Impossible promotion.
-				return ErrInvalidMoveNotation
-			}
+	f, err := os.OpenFile(e.pgnFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		e.logger.Print("*** cannot open pgn file: ", err)
+		return
+	}
+	defer f.Close()
+	if err := NewPGNWriter(f).Write(game); err != nil {
+		e.logger.Print("*** cannot write pgn file: ", err)
+	}
+}
+
+// Quit shuts everything down gracefully and returns.
+// The current game is written to the PGN file.
+func (e *Engine) Quit() {
+	e.StopSearch()
+	e.writeGame()
+}
+
+// SetPosition stops a running search and sets up the position given by fen,
+// or the starting position if fen is empty. Then all moves are played in order.
+// If an error occurs the engine keeps its previous position.
+func (e *Engine) SetPosition(fen string, moves []string) error {
+	e.StopSearch()
+	old, oldHistory := e.board, e.history.Copy()
+	oldStart, oldMoves := e.gameStart, e.gameMoves
+
+	e.board = NewBoard()
+	e.history.Reset()
+	if fen != "" {
+		if err := e.board.SetFEN(fen); err != nil {
+			e.board, e.history = old, oldHistory
+			return err
+		}
+	}
+	e.gameStart, e.gameMoves = e.board, nil
+	for _, m := range moves {
+		if err := e.MakeMove(m); err != nil {
+			e.board, e.history = old, oldHistory
+			e.gameStart, e.gameMoves = oldStart, oldMoves
+			return fmt.Errorf("move %s: %w", m, err)
 		}
-		from = from.To0x88()
-		to = to.To0x88()
-		bm := NewBitMove(from, to, promo)
+	}
 
-		e.logger.Print("*** exec move: ", bm.MiniNotation())
+	return nil
+}
 
-		// TODO - test if move is really legal?
+func (e *Engine) GetLegalMoves() MoveList {
+	ml := MoveList{}
+	e.board.GenerateAllLegalMoves(&ml)
+	return ml
+}
 
-		e.logger.Print("\n" + e.board.String())
-		e.logger.Print("\n" + e.board.InfoBoardString())
-		e.board.MakeLegalMove(bm)
+// MakeMove stops a running search and plays a move given in coordinate notation.
+// An error is returned and the board is unchanged if the move is not legal.
+func (e *Engine) MakeMove(move string) error {
+	e.StopSearch()
+	bm, err := e.board.ParseMove(move)
+	if err != nil {
+		return err
 	}
 
+	e.logger.Print("*** exec move: ", bm.MiniNotation(), " (", e.board.MoveToSAN(bm), ")")
+	e.history.Push(e.board.Hash)
+	e.gameMoves = append(e.gameMoves, bm)
+	e.board.MakeLegalMove(bm)
+
 	return nil
 }
+
+// IsThreefoldRepetition returns true if the current position occurred
+// for the third time in the game.
+func (e *Engine) IsThreefoldRepetition() bool {
+	return e.history.IsThreefold(&e.board)
+}
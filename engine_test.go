@@ -0,0 +1,100 @@
+package chesskimo
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestSearchLimits tests if every search function ends by the limits of a search without a clock.
+func TestSearchLimits(t *testing.T) {
+	for _, search := range []NamedSearch{ALPHA_BETA_SEARCH, MONTE_CARLO_SEARCH} {
+		for _, ss := range []SearchSettings{{Nodes: 50}, {WTime: MIN_CLOCK_TIME, BTime: MIN_CLOCK_TIME}} {
+			engine := NewEngine("test", "test", nil, search)
+			done := make(chan SearchResult, 1)
+			engine.StartSearch(ss, func(sr SearchResult) {
+				done <- sr
+			})
+
+			select {
+			case sr := <-done:
+				if sr.Move == BitMove(0) {
+					t.Fatalf("Expected a best move for %+v\n", ss)
+				}
+			case <-time.After(2 * time.Second):
+				t.Fatalf("Search did not finish for %+v\n", ss)
+			}
+			engine.Quit()
+		}
+	}
+}
+
+// TestSearchWithoutMoves tests if every search function ends at once if the player to move is mated.
+func TestSearchWithoutMoves(t *testing.T) {
+	for _, search := range []NamedSearch{ALPHA_BETA_SEARCH, MONTE_CARLO_SEARCH} {
+		engine := NewEngine("test", "test", nil, search)
+		// Fool's mate.
+		if err := engine.SetPosition("", []string{"f2f3", "e7e5", "g2g4", "d8h4"}); err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		done := make(chan SearchResult, 1)
+		engine.StartSearch(SearchSettings{Nodes: 100}, func(sr SearchResult) {
+			done <- sr
+		})
+
+		select {
+		case sr := <-done:
+			if sr.Move != BitMove(0) {
+				t.Fatalf("Expected no move but got %s\n", sr.Move.MiniNotation())
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("Search did not finish in a mated position\n")
+		}
+		engine.Quit()
+	}
+}
+
+// TestEngineWritesPGN tests if the games of the engine are written to the PGN file.
+func TestEngineWritesPGN(t *testing.T) {
+	dir, err := ioutil.TempDir("", "chesskimo")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "games.pgn")
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	if err := engine.SetOption("PGNFile", path); err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	// The engine plays the mate as black. The frontend does not send the final position.
+	if err := engine.SetPosition("", []string{"f2f3", "e7e5", "g2g4"}); err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	engine.Search(&SearchSettings{MaxDepth: 2})
+	engine.NewGame()
+	// Games without moves are not written.
+	engine.Quit()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	game, err := NewPGNReader(bytes.NewReader(data)).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n%s\n", err, data)
+	}
+	if game.Result != "0-1" || game.Tag("Black") != "test" || len(game.Moves) != 4 {
+		t.Fatalf("Unexpected game:\n%s\n", data)
+	}
+	if last := game.Moves[3]; last.SAN != "Qh4#" || !strings.HasPrefix(last.Comment, "+M1/") {
+		t.Fatalf("Unexpected last move %s {%s}\n", last.SAN, last.Comment)
+	}
+	if strings.Count(string(data), "[Event ") != 1 {
+		t.Fatalf("Expected a single game:\n%s\n", data)
+	}
+}
@@ -1,24 +1,73 @@
 package chesskimo
 
+import (
+	"time"
+)
+
 // SearchResult contains all relevant info that should
 // be returned from a best move search.
 type SearchResult struct {
 	Move  BitMove
 	Score int
 	Depth int
+	// PV is the principal variation starting with Move.
+	PV       []BitMove
+	SelDepth int
+	Nodes    uint64
+	Time     time.Duration
 }
 
 // SearchSettings defines constraints that may exist for
-// the search.
+// the search. Zero values mean that there is no such constraint.
 type SearchSettings struct {
-	MaxDepth int
+	MaxDepth  int
+	WTime     time.Duration
+	BTime     time.Duration
+	WInc      time.Duration
+	BInc      time.Duration
+	MovesToGo int
+	Nodes     uint64
+	Mate      int
+	MoveTime  time.Duration
+	Infinite  bool
+}
+
+// SearchInfo contains the progress of a running search. It is sent to the
+// frontend after every iteration and periodically during long iterations.
+// Intermediate reports have no principal variation and no score.
+type SearchInfo struct {
+	Depth    int
+	SelDepth int
+	Score    int
+	Nodes    uint64
+	Time     time.Duration
+	HashFull int
+	PV       []BitMove
+	// MultiPV is the rank of the variation if more than one is searched, otherwise 0.
+	MultiPV int
+	// String is a message for the user. An info with a message carries no other data.
+	String string
 }
 
 // SearchFun function type defines how a search function
 // must be defined.
 type SearchFun func(*Engine, *SearchSettings, *uint32) SearchResult
 
+// NamedSearch is a search function with the value of the Search option that selects it.
+type NamedSearch struct {
+	Name string
+	Fun  SearchFun
+}
+
+var (
+	// ALPHA_BETA_SEARCH and MONTE_CARLO_SEARCH are the searches the Search option offers.
+	ALPHA_BETA_SEARCH  = NamedSearch{Name: "AlphaBeta", Fun: AlphaBetaSearch}
+	MONTE_CARLO_SEARCH = NamedSearch{Name: "MonteCarlo", Fun: SimpleMCSearch}
+)
+
 // Communicator defines how the chess engine talks to the GUI (or other frontends).
 type Communicator interface {
 	RunInputOutputLoop(engine *Engine)
+	// SendInfo reports the progress of a search. It is called from the search goroutine.
+	SendInfo(info SearchInfo)
 }
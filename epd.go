@@ -0,0 +1,194 @@
+package chesskimo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+var (
+	// ErrEPDFieldsInvalid indicates that an EPD record has less than four position fields.
+	ErrEPDFieldsInvalid = errors.New("EPD has invalid fields encoding")
+	// ErrEPDOperationInvalid indicates that an EPD record contains a malformed operation.
+	ErrEPDOperationInvalid = errors.New("EPD has invalid operation")
+)
+
+// EPD is an Extended Position Description record: a position without move
+// counters followed by operations, e.g. 'bm Qg6; id "WAC.001";'.
+// The move counters of the position are taken from the 'hmvc' and 'fmvn' operations.
+type EPD struct {
+	MinBoard
+	// Ops maps every opcode to its operands. Quotes of string operands are removed.
+	Ops map[string][]string
+	// Opcodes contains the opcodes in the order they appeared.
+	Opcodes []string
+}
+
+// ParseEPD parses an EPD record.
+func ParseEPD(epd string) (EPD, error) {
+	e := EPD{MinBoard: NewMinBoard(), Ops: map[string][]string{}}
+
+	// The position is given by the first four fields.
+	rest := strings.TrimSpace(epd)
+	fields := make([]string, 0, 4)
+	for len(fields) < 4 {
+		if rest == "" {
+			return e, ErrEPDFieldsInvalid
+		}
+		idx := strings.IndexAny(rest, " \t")
+		if idx < 0 {
+			idx = len(rest)
+		}
+		fields = append(fields, rest[:idx])
+		rest = strings.TrimSpace(rest[idx:])
+	}
+
+	pieces, err := parseFENPieces(fields[0])
+	if err != nil {
+		return e, err
+	}
+	color, err := parseFENColor(fields[1])
+	if err != nil {
+		return e, err
+	}
+	short, long := parseFENCastlingRights(fields[2])
+	epSq, err := parseFENEnPassent(fields[3])
+	if err != nil {
+		return e, err
+	}
+
+	e.Squares = pieces
+	e.Color = color
+	e.CastleShort = short
+	e.CastleLong = long
+	e.EpSquare = epSq
+	e.HalfMoves = 0
+	e.MoveNum = 1
+
+	if err := e.parseOperations(rest); err != nil {
+		return e, err
+	}
+
+	if n, ok := e.IntOperand("hmvc"); ok {
+		if n < 0 {
+			return e, ErrEPDOperationInvalid
+		}
+		e.HalfMoves = uint16(n)
+	}
+	if n, ok := e.IntOperand("fmvn"); ok {
+		if n < 0 {
+			return e, ErrEPDOperationInvalid
+		}
+		e.MoveNum = uint16(n)
+	}
+
+	return e, nil
+}
+
+// parseOperations parses the operations of an EPD record. Every operation is an
+// opcode followed by any number of operands and is terminated by a semicolon.
+// String operands are enclosed in quotes and may contain spaces and semicolons.
+func (e *EPD) parseOperations(ops string) error {
+	tokens := []string{}
+	for i := 0; i < len(ops); i++ {
+		c := ops[i]
+		switch {
+		case c == ' ' || c == '\t':
+			continue
+		case c == ';':
+			if len(tokens) == 0 {
+				return ErrEPDOperationInvalid
+			}
+			e.addOperation(tokens[0], tokens[1:])
+			tokens = []string{}
+		case c == '"':
+			end := strings.IndexByte(ops[i+1:], '"')
+			if end < 0 || len(tokens) == 0 {
+				return ErrEPDOperationInvalid
+			}
+			tokens = append(tokens, ops[i+1:i+1+end])
+			i += end + 1
+		default:
+			end := strings.IndexAny(ops[i:], " \t;")
+			if end < 0 {
+				end = len(ops) - i
+			}
+			tokens = append(tokens, ops[i:i+end])
+			i += end - 1
+		}
+	}
+
+	// The last operation may miss its semicolon.
+	if len(tokens) > 0 {
+		e.addOperation(tokens[0], tokens[1:])
+	}
+	return nil
+}
+
+func (e *EPD) addOperation(opcode string, operands []string) {
+	if _, ok := e.Ops[opcode]; !ok {
+		e.Opcodes = append(e.Opcodes, opcode)
+	}
+	e.Ops[opcode] = operands
+}
+
+// Operands returns all operands of opcode or nil if the operation does not exist.
+func (e *EPD) Operands(opcode string) []string {
+	return e.Ops[opcode]
+}
+
+// Operand returns the first operand of opcode or "" if there is none.
+func (e *EPD) Operand(opcode string) string {
+	if ops := e.Ops[opcode]; len(ops) > 0 {
+		return ops[0]
+	}
+	return ""
+}
+
+// IntOperand returns the first operand of opcode as integer. The second
+// return value is false if there is no such operand or it is not a number.
+func (e *EPD) IntOperand(opcode string) (int, bool) {
+	n, err := strconv.Atoi(e.Operand(opcode))
+	return n, err == nil
+}
+
+// ID returns the identifier of the position (opcode 'id').
+func (e *EPD) ID() string {
+	return e.Operand("id")
+}
+
+// Board returns the position of the record.
+func (e *EPD) Board() Board {
+	b := NewBoard()
+	b.SetMinBoard(e.MinBoard)
+	return b
+}
+
+// ToEPD returns the EPD record. The move counters are not part of the position fields.
+func (e *EPD) ToEPD() string {
+	fen := strings.Fields(e.ToFEN())
+	var sb strings.Builder
+	sb.WriteString(strings.Join(fen[:4], " "))
+
+	for _, opcode := range e.Opcodes {
+		sb.WriteString(" " + opcode)
+		for _, operand := range e.Ops[opcode] {
+			if strings.ContainsAny(operand, " ;\t") || operand == "" || !isEPDSymbol(opcode, operand) {
+				operand = "\"" + operand + "\""
+			}
+			sb.WriteString(" " + operand)
+		}
+		sb.WriteString(";")
+	}
+	return sb.String()
+}
+
+// isEPDSymbol returns false for operands that must be written as string.
+// Identifiers and comments are always strings.
+func isEPDSymbol(opcode, operand string) bool {
+	switch opcode {
+	case "id", "c0", "c1", "c2", "c3", "c4", "c5", "c6", "c7", "c8", "c9":
+		return false
+	}
+	return true
+}
@@ -0,0 +1,56 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestParseEPD tests the parsing of positions and operations of EPD records.
+func TestParseEPD(t *testing.T) {
+	epd, err := ParseEPD(`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - bm Qg6; id "WAC.001"; c0 "a comment; with semicolon"; acd 12; ce +350; hmvc 3; fmvn 27;`)
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	if fen := epd.ToFEN(); fen != "2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - 3 27" {
+		t.Fatalf("Unexpected position %s\n", fen)
+	}
+	if epd.ID() != "WAC.001" || epd.Operand("bm") != "Qg6" || epd.Operand("c0") != "a comment; with semicolon" {
+		t.Fatalf("Unexpected operations %v\n", epd.Ops)
+	}
+	if n, ok := epd.IntOperand("ce"); !ok || n != 350 {
+		t.Fatalf("Expected ce 350 but got %d\n", n)
+	}
+	if n, ok := epd.IntOperand("acd"); !ok || n != 12 {
+		t.Fatalf("Expected acd 12 but got %d\n", n)
+	}
+
+	// Several operands and a missing final semicolon.
+	epd, err = ParseEPD("r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - am Nxc6 Bd3 ;dm 3")
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	if ops := epd.Operands("am"); len(ops) != 2 || ops[0] != "Nxc6" || ops[1] != "Bd3" {
+		t.Fatalf("Unexpected am operands %v\n", ops)
+	}
+	if n, ok := epd.IntOperand("dm"); !ok || n != 3 {
+		t.Fatalf("Expected dm 3 but got %d\n", n)
+	}
+	if out := epd.ToEPD(); out != "r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - am Nxc6 Bd3; dm 3;" {
+		t.Fatalf("Unexpected EPD output %s\n", out)
+	}
+	board := epd.Board()
+	if fen := board.FEN(); fen != "r1b1k2r/ppppnppp/2n2q2/2b5/3NP3/2P1B3/PP3PPP/RN1QKB1R w KQkq - 0 1" {
+		t.Fatalf("Unexpected board %s\n", fen)
+	}
+
+	invalid := []string{
+		"2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w -",
+		"2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 x - - bm Qg6;",
+		`2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - id "unterminated;`,
+		"2rr3k/pp3pp1/1nnqbN1p/3pN3/2pP4/2P3Q1/PPB4P/R4RK1 w - - ;",
+	}
+	for _, s := range invalid {
+		if _, err := ParseEPD(s); err == nil {
+			t.Fatalf("Expected an error for %s\n", s)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package chesskimo
+
+const (
+	// Game phase weights of the pieces. The phase is used to taper
+	// between middlegame and endgame scores.
+	PHASE_KNIGHT = 1
+	PHASE_BISHOP = 1
+	PHASE_ROOK   = 2
+	PHASE_QUEEN  = 4
+	PHASE_TOTAL  = 4*PHASE_KNIGHT + 4*PHASE_BISHOP + 4*PHASE_ROOK + 2*PHASE_QUEEN
+)
+
+var (
+	// MaterialValues contains the value of each piece type in centipawns.
+	// These values are used wherever a single number per piece is needed.
+	MaterialValues = [KING + 1]int{
+		PAWN:   100,
+		KNIGHT: 320,
+		BISHOP: 330,
+		ROOK:   500,
+		QUEEN:  900,
+	}
+
+	// Material values of the pieces for the middlegame and the endgame.
+	materialMG = [KING + 1]int{PAWN: 82, KNIGHT: 337, BISHOP: 365, ROOK: 477, QUEEN: 1025}
+	materialEG = [KING + 1]int{PAWN: 94, KNIGHT: 281, BISHOP: 297, ROOK: 512, QUEEN: 936}
+
+	// The piece-square tables below are written from white's point of view
+	// as seen on a diagram: the first row is rank 8, the last row is rank 1.
+	pawnTableMG = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		10, 10, 20, 30, 30, 20, 10, 10,
+		5, 5, 10, 25, 25, 10, 5, 5,
+		0, 0, 0, 20, 20, 0, 0, 0,
+		5, -5, -10, 0, 0, -10, -5, 5,
+		5, 10, 10, -20, -20, 10, 10, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	pawnTableEG = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		80, 80, 80, 80, 80, 80, 80, 80,
+		50, 50, 50, 50, 50, 50, 50, 50,
+		30, 30, 30, 30, 30, 30, 30, 30,
+		15, 15, 15, 15, 15, 15, 15, 15,
+		5, 5, 5, 5, 5, 5, 5, 5,
+		0, 0, 0, 0, 0, 0, 0, 0,
+		0, 0, 0, 0, 0, 0, 0, 0,
+	}
+	knightTable = [64]int{
+		-50, -40, -30, -30, -30, -30, -40, -50,
+		-40, -20, 0, 0, 0, 0, -20, -40,
+		-30, 0, 10, 15, 15, 10, 0, -30,
+		-30, 5, 15, 20, 20, 15, 5, -30,
+		-30, 0, 15, 20, 20, 15, 0, -30,
+		-30, 5, 10, 15, 15, 10, 5, -30,
+		-40, -20, 0, 5, 5, 0, -20, -40,
+		-50, -40, -30, -30, -30, -30, -40, -50,
+	}
+	bishopTable = [64]int{
+		-20, -10, -10, -10, -10, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 10, 10, 5, 0, -10,
+		-10, 5, 5, 10, 10, 5, 5, -10,
+		-10, 0, 10, 10, 10, 10, 0, -10,
+		-10, 10, 10, 10, 10, 10, 10, -10,
+		-10, 5, 0, 0, 0, 0, 5, -10,
+		-20, -10, -10, -10, -10, -10, -10, -20,
+	}
+	rookTable = [64]int{
+		0, 0, 0, 0, 0, 0, 0, 0,
+		5, 10, 10, 10, 10, 10, 10, 5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		-5, 0, 0, 0, 0, 0, 0, -5,
+		0, 0, 0, 5, 5, 0, 0, 0,
+	}
+	queenTable = [64]int{
+		-20, -10, -10, -5, -5, -10, -10, -20,
+		-10, 0, 0, 0, 0, 0, 0, -10,
+		-10, 0, 5, 5, 5, 5, 0, -10,
+		-5, 0, 5, 5, 5, 5, 0, -5,
+		0, 0, 5, 5, 5, 5, 0, -5,
+		-10, 5, 5, 5, 5, 5, 0, -10,
+		-10, 0, 5, 0, 0, 0, 0, -10,
+		-20, -10, -10, -5, -5, -10, -10, -20,
+	}
+	kingTableMG = [64]int{
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-30, -40, -40, -50, -50, -40, -40, -30,
+		-20, -30, -30, -40, -40, -30, -30, -20,
+		-10, -20, -20, -20, -20, -20, -20, -10,
+		20, 20, 0, 0, 0, 0, 20, 20,
+		20, 30, 10, 0, 0, 10, 30, 20,
+	}
+	kingTableEG = [64]int{
+		-50, -40, -30, -20, -20, -30, -40, -50,
+		-30, -20, -10, 0, 0, -10, -20, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 30, 40, 40, 30, -10, -30,
+		-30, -10, 20, 30, 30, 20, -10, -30,
+		-30, -30, 0, 0, 0, 0, -30, -30,
+		-50, -30, -30, -30, -30, -30, -30, -50,
+	}
+
+	// PSTMiddlegame and PSTEndgame contain material plus positional value
+	// of every piece (including color) on every 0x88 square.
+	PSTMiddlegame = [WKING + 1][64 * 2]int{}
+	PSTEndgame    = [WKING + 1][64 * 2]int{}
+)
+
+func init() {
+	populatePieceSquareTables()
+}
+
+func populatePieceSquareTables() {
+	type tables struct {
+		ptype  Piece
+		mg, eg *[64]int
+	}
+	all := []tables{
+		{PAWN, &pawnTableMG, &pawnTableEG},
+		{KNIGHT, &knightTable, &knightTable},
+		{BISHOP, &bishopTable, &bishopTable},
+		{ROOK, &rookTable, &rookTable},
+		{QUEEN, &queenTable, &queenTable},
+		{KING, &kingTableMG, &kingTableEG},
+	}
+
+	for _, t := range all {
+		for _, sq := range Lookup0x88 {
+			rank, file := int(sq.Rank()), int(sq.File())
+			// White reads the diagram upside down, black reads it mirrored.
+			widx := (7-rank)*8 + file
+			bidx := rank*8 + file
+
+			PSTMiddlegame[t.ptype|WHITE][sq] = materialMG[t.ptype] + t.mg[widx]
+			PSTEndgame[t.ptype|WHITE][sq] = materialEG[t.ptype] + t.eg[widx]
+			PSTMiddlegame[t.ptype|BLACK][sq] = materialMG[t.ptype] + t.mg[bidx]
+			PSTEndgame[t.ptype|BLACK][sq] = materialEG[t.ptype] + t.eg[bidx]
+		}
+	}
+}
+
+// Evaluate returns the static evaluation of the board in centipawns. The score
+// is relative to the player to move: positive values favor the side to move.
+// Middlegame and endgame scores are interpolated by the current game phase.
+func Evaluate(b *Board) int {
+	mg, eg := [2]int{}, [2]int{}
+	phase := 0
+
+	for color := BLACK; color <= WHITE; color++ {
+		evalPieceList(&b.Pawns[color], PAWN|color, &mg[color], &eg[color])
+		evalPieceList(&b.Knights[color], KNIGHT|color, &mg[color], &eg[color])
+		evalPieceList(&b.Bishops[color], BISHOP|color, &mg[color], &eg[color])
+		evalPieceList(&b.Rooks[color], ROOK|color, &mg[color], &eg[color])
+		evalPieceList(&b.Queens[color], QUEEN|color, &mg[color], &eg[color])
+
+		kingSq := b.Kings[color]
+		if kingSq.OnBoard() {
+			mg[color] += PSTMiddlegame[KING|color][kingSq]
+			eg[color] += PSTEndgame[KING|color][kingSq]
+		}
+
+		phase += int(b.Knights[color].Size)*PHASE_KNIGHT +
+			int(b.Bishops[color].Size)*PHASE_BISHOP +
+			int(b.Rooks[color].Size)*PHASE_ROOK +
+			int(b.Queens[color].Size)*PHASE_QUEEN
+	}
+
+	// Promotions can push the phase above its starting value.
+	if phase > PHASE_TOTAL {
+		phase = PHASE_TOTAL
+	}
+
+	us, them := b.Player, b.Player.Flip()
+	mgScore := mg[us] - mg[them]
+	egScore := eg[us] - eg[them]
+
+	return (mgScore*phase + egScore*(PHASE_TOTAL-phase)) / PHASE_TOTAL
+}
+
+// evalPieceList adds the middlegame and endgame values of all pieces in plist.
+func evalPieceList(plist *PieceList, piece Piece, mg, eg *int) {
+	for i := uint8(0); i < plist.Size; i++ {
+		sq := plist.Pieces[i]
+		*mg += PSTMiddlegame[piece][sq]
+		*eg += PSTEndgame[piece][sq]
+	}
+}
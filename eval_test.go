@@ -0,0 +1,70 @@
+package chesskimo
+
+import (
+	"strings"
+	"testing"
+)
+
+// mirrorFEN flips a FEN vertically and swaps the colors of all pieces,
+// the side to move and the castling rights.
+func mirrorFEN(fen string) string {
+	fields := strings.Fields(fen)
+	ranks := strings.Split(fields[0], "/")
+	for i, j := 0, len(ranks)-1; i < j; i, j = i+1, j-1 {
+		ranks[i], ranks[j] = ranks[j], ranks[i]
+	}
+	swapCase := func(r rune) rune {
+		if r >= 'a' && r <= 'z' {
+			return r - 'a' + 'A'
+		} else if r >= 'A' && r <= 'Z' {
+			return r - 'A' + 'a'
+		}
+		return r
+	}
+	fields[0] = strings.Map(swapCase, strings.Join(ranks, "/"))
+	if fields[1] == "w" {
+		fields[1] = "b"
+	} else {
+		fields[1] = "w"
+	}
+	if fields[2] != "-" {
+		fields[2] = strings.Map(swapCase, fields[2])
+	}
+	if fields[3] != "-" {
+		rank := '3'
+		if fields[3][1] == '3' {
+			rank = '6'
+		}
+		fields[3] = fields[3][:1] + string(rank)
+	}
+
+	return strings.Join(fields, " ")
+}
+
+// TestEvaluateSymmetry tests if the evaluation is equal for mirrored positions.
+func TestEvaluateSymmetry(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+	}
+
+	board := NewBoard()
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		score := Evaluate(&board)
+		mirrored := mirrorFEN(fen)
+		board.SetFEN(mirrored)
+		mscore := Evaluate(&board)
+		if score != mscore {
+			t.Fatalf("Evaluation of FEN %s is %d but mirrored FEN %s is %d\n", fen, score, mirrored, mscore)
+		}
+	}
+
+	board.SetStartingPosition()
+	if score := Evaluate(&board); score != 0 {
+		t.Fatalf("Evaluation of the starting position should be 0 but is %d\n", score)
+	}
+}
@@ -107,6 +107,73 @@ func ParseFEN(fen string) (MinBoard, error) {
 	return mb, nil
 }
 
+// ToFEN returns the FEN record of the MinBoard.
+func (mb *MinBoard) ToFEN() string {
+	var sb strings.Builder
+
+	// Pieces from rank 8 down to rank 1.
+	for rank := 7; rank >= 0; rank-- {
+		empty := 0
+		for file := 0; file < 8; file++ {
+			piece := mb.Squares[rank*8+file]
+			if piece.IsEmpty() {
+				empty++
+				continue
+			}
+			if empty > 0 {
+				sb.WriteString(strconv.Itoa(empty))
+				empty = 0
+			}
+			sb.WriteString(PrintMap[piece])
+		}
+		if empty > 0 {
+			sb.WriteString(strconv.Itoa(empty))
+		}
+		if rank > 0 {
+			sb.WriteByte('/')
+		}
+	}
+
+	// Active color.
+	if mb.Color == WHITE {
+		sb.WriteString(" w ")
+	} else {
+		sb.WriteString(" b ")
+	}
+
+	// Castling rights.
+	castling := ""
+	if mb.CastleShort[WHITE] {
+		castling += "K"
+	}
+	if mb.CastleLong[WHITE] {
+		castling += "Q"
+	}
+	if mb.CastleShort[BLACK] {
+		castling += "k"
+	}
+	if mb.CastleLong[BLACK] {
+		castling += "q"
+	}
+	if castling == "" {
+		castling = "-"
+	}
+	sb.WriteString(castling)
+
+	// En passent target square.
+	if mb.EpSquare != OTB {
+		sb.WriteString(" " + PrintBoardIndex[Lookup0x88[mb.EpSquare]])
+	} else {
+		sb.WriteString(" -")
+	}
+
+	// Half move clock and full move number.
+	sb.WriteString(" " + strconv.Itoa(int(mb.HalfMoves)))
+	sb.WriteString(" " + strconv.Itoa(int(mb.MoveNum)))
+
+	return sb.String()
+}
+
 // SplitFields splits a FEN into its fields and returns them separated into a slice,
 // or an error if the amount of fields is not equal 6.
 func splitFENFields(fen string) ([]string, error) {
@@ -166,3 +166,59 @@ func TestParseMoveNumber(t *testing.T) {
 		}
 	}
 }
+
+// TestFENRoundTrip tests if FENs survive parsing and serialization unchanged.
+func TestFENRoundTrip(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"rnbqkb1r/pp2pppp/5n2/2ppP3/3P4/8/PPP2PPP/RNBQKBNR w KQkq d6 0 4",
+		"r2qkbnr/pp2p1Pp/1np1b3/4P3/1PpP1P2/8/P6p/RNBQKBN1 b Qkq b3 0 12",
+		"r3k2r/pppq1ppp/2npbn2/2b1p3/2B1P3/2NPBN2/PPPQ1PPP/R3K2R w Kq - 4 8",
+		"8/5k2/2p5/2R5/2p1b3/2K5/1r2R3/1R3n2 b - - 49 73",
+	}
+
+	board := NewBoard()
+	for _, fen := range fens {
+		mb, err := ParseFEN(fen)
+		if err != nil {
+			t.Fatalf("Expected pass, FEN is %s\n", fen)
+		}
+		if out := mb.ToFEN(); out != fen {
+			t.Fatalf("MinBoard FEN should be %s but is %s\n", fen, out)
+		}
+
+		board.SetFEN(fen)
+		if out := board.FEN(); out != fen {
+			t.Fatalf("Board FEN should be %s but is %s\n", fen, out)
+		}
+	}
+}
+
+// TestFENAfterMoves tests the FEN of positions reached by making moves.
+func TestFENAfterMoves(t *testing.T) {
+	results := []string{
+		"rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPP1PPP/RNBQKBNR w KQkq c6 0 2",
+		"rnbqkbnr/pp1ppppp/8/2p5/4P3/8/PPPPKPPP/RNBQ1BNR b kq - 1 2",
+		"rnbqkbnr/pp1ppp1p/6p1/2p5/4P3/8/PPPPKPPP/RNBQ1BNR w kq - 0 3",
+		"rnbqkbnr/pp1ppp1p/6p1/2p5/4P3/5N2/PPPPKPPP/RNBQ1B1R b kq - 1 3",
+	}
+	moves := []BitMove{
+		NewBitMove(0x14, 0x34, NONE),
+		NewBitMove(0x62, 0x42, NONE),
+		NewBitMove(0x04, 0x14, NONE),
+		NewBitMove(0x66, 0x56, NONE),
+		NewBitMove(0x06, 0x25, NONE),
+	}
+
+	board := NewBoard()
+	for i, m := range moves {
+		board.MakeLegalMove(m)
+		if out := board.FEN(); out != results[i] {
+			t.Fatalf("FEN after move %s should be %s but is %s\n", m.MiniNotation(), results[i], out)
+		}
+	}
+}
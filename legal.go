@@ -0,0 +1,134 @@
+package chesskimo
+
+// IsPseudoLegal tests if m is a move of the player to move that obeys the movement rules
+// of the moving piece, ignoring whether it leaves the own king in check. The move may
+// come from anywhere, e.g. the transposition table or user input.
+func (b *Board) IsPseudoLegal(m BitMove) bool {
+	from, to, promo := m.All()
+	if !from.OnBoard() || !to.OnBoard() || from == to {
+		return false
+	}
+	color := b.Player
+	piece := b.Squares[from]
+	if piece.IsEmpty() || !piece.HasColor(color) {
+		return false
+	}
+	target := b.Squares[to]
+	if !target.IsEmpty() && target.HasColor(color) {
+		return false
+	}
+	ptype := piece & PIECE_MASK
+
+	// Only pawns reaching the last rank promote and they must do so.
+	if ptype == PAWN && to.IsPawnPromoting(color) {
+		if promo != QUEEN && promo != ROOK && promo != BISHOP && promo != KNIGHT {
+			return false
+		}
+	} else if promo != NONE {
+		return false
+	}
+
+	switch ptype {
+	case PAWN:
+		push := PAWN_PUSH_DIRS[color]
+		switch int8(to) - int8(from) {
+		case push:
+			return target.IsEmpty()
+		case 2 * push:
+			between := Square(int8(from) + push)
+			return from.IsPawnBaseRank(color) && b.Squares[between].IsEmpty() && target.IsEmpty()
+		case PAWN_CAPTURE_DIRS[color][0], PAWN_CAPTURE_DIRS[color][1]:
+			return !target.IsEmpty() || to == b.EpSquare
+		}
+		return false
+	case KNIGHT:
+		return SQUARE_DIFFS[from.Diff(to)].Contains(KNIGHT)
+	case BISHOP, ROOK, QUEEN:
+		diff := from.Diff(to)
+		if !SQUARE_DIFFS[diff].Contains(ptype) {
+			return false
+		}
+		// All squares between from and to must be empty.
+		dir := DIFF_DIRS[diff]
+		for sq := Square(int8(from) + dir); sq != to; sq = Square(int8(sq) + dir) {
+			if !b.Squares[sq].IsEmpty() {
+				return false
+			}
+		}
+		return true
+	case KING:
+		if SQUARE_DIFFS[from.Diff(to)].Contains(KING) {
+			return true
+		}
+		if from == CASTLING_DETECT_SHORT[color][0] && to == CASTLING_DETECT_SHORT[color][1] {
+			return b.CastleShort[color] &&
+				b.Squares[CASTLING_PATH_SHORT[color][0]].IsEmpty() && b.Squares[CASTLING_PATH_SHORT[color][1]].IsEmpty()
+		}
+		if from == CASTLING_DETECT_LONG[color][0] && to == CASTLING_DETECT_LONG[color][1] {
+			return b.CastleLong[color] &&
+				b.Squares[CASTLING_PATH_LONG[color][0]].IsEmpty() && b.Squares[CASTLING_PATH_LONG[color][1]].IsEmpty() &&
+				b.Squares[CASTLING_PATH_LONG[color][2]].IsEmpty()
+		}
+	}
+
+	return false
+}
+
+// IsLegal tests if m is a legal move of the player to move. It detects the checks and pins
+// of the position and applies the same rules as the move generators, but only to m.
+func (b *Board) IsLegal(m BitMove) bool {
+	if !b.IsPseudoLegal(m) {
+		return false
+	}
+	b.DetectChecksAndPins(b.Player)
+
+	from, to, promo := m.All()
+	color := b.Player
+	ptype := b.Squares[from] & PIECE_MASK
+
+	if ptype == KING {
+		if SQUARE_DIFFS[from.Diff(to)].Contains(KING) {
+			return !b.IsSquareAttacked(to, OTB, color) && !b.Squares[to.ToInfoIndex()].IsSet(INFO_MASK_FORBIDDEN_ESCAPE)
+		}
+		// Castling: the king may not be in check, nor pass or reach an attacked square.
+		if b.CheckInfo != CHECK_NONE {
+			return false
+		}
+		pass := CASTLING_PATH_SHORT[color][0]
+		if to == CASTLING_DETECT_LONG[color][1] {
+			pass = CASTLING_PATH_LONG[color][0]
+		}
+		return !b.IsSquareAttacked(pass, OTB, color) && !b.IsSquareAttacked(to, OTB, color)
+	}
+
+	if b.CheckInfo == CHECK_DOUBLE_CHECK {
+		// Only the king can escape a double check.
+		return false
+	}
+
+	if ptype == PAWN {
+		// Pawn moves are tested like in the generator, en passent by playing it.
+		target := b.Squares[to]
+		eptype := uint8(EP_TYPE_NONE)
+		if to == b.EpSquare && target.IsEmpty() && from.File() != to.File() {
+			// The captured pawn is not on the target square.
+			eptype = EP_TYPE_CAPTURE
+			target = PAWN | color.Flip()
+		}
+		_, legal := b.newPawnMoveIfLegal(color, from, to, PAWN|color, target, promo, eptype)
+		return legal
+	}
+
+	fromInfo := b.Squares[from.ToInfoIndex()]
+	toInfo := b.Squares[to.ToInfoIndex()]
+	if fromInfo.Pinval() != 0 && toInfo.Pinval() != fromInfo.Pinval() {
+		// A pinned piece must stay on the line of the pin.
+		return false
+	}
+	if b.CheckInfo.OnBoard() && !toInfo.IsSet(INFO_MASK_CHECK) {
+		// The check must be blocked or the checker captured.
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,67 @@
+package chesskimo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestIsLegal compares IsLegal and IsPseudoLegal with the move generator for random
+// moves in the positions of random games starting from the perft positions.
+func TestIsLegal(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+		"rnbqkb1r/pp2pppp/5n2/2ppP3/3P4/8/PPP2PPP/RNBQKBNR w KQkq d6 0 4",
+		"r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1",
+		"8/8/8/2k5/2pP4/8/B7/4K3 b - d3 0 1",
+	}
+	promotions := []Piece{NONE, NONE, NONE, NONE, KNIGHT, BISHOP, ROOK, QUEEN}
+	rnd := rand.New(rand.NewSource(1))
+
+	// Legal moves of other positions are good candidates because they are plausible.
+	candidates := []BitMove{}
+	board := NewBoard()
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		for ply := 0; ply < 40; ply++ {
+			mlist := MoveList{}
+			board.GenerateAllLegalMoves(&mlist)
+			if mlist.Size == 0 {
+				break
+			}
+			legal := map[BitMove]bool{}
+			for i := uint32(0); i < mlist.Size; i++ {
+				legal[mlist.Moves[i]] = true
+				candidates = append(candidates, mlist.Moves[i])
+			}
+
+			tests := make([]BitMove, 0, 300)
+			for i := 0; i < 100; i++ {
+				tests = append(tests, candidates[rnd.Intn(len(candidates))])
+				from, to := Lookup0x88[rnd.Intn(64)], Lookup0x88[rnd.Intn(64)]
+				tests = append(tests, NewBitMove(from, to, promotions[rnd.Intn(len(promotions))]))
+			}
+			for i := uint32(0); i < mlist.Size; i++ {
+				tests = append(tests, mlist.Moves[i])
+			}
+
+			for _, m := range tests {
+				cpy := board
+				if got := board.IsLegal(m); got != legal[m] {
+					t.Fatalf("IsLegal(%s) is %v in FEN %s\n", m.MiniNotation(), got, board.FEN())
+				}
+				if legal[m] && !board.IsPseudoLegal(m) {
+					t.Fatalf("Legal move %s is not pseudo legal in FEN %s\n", m.MiniNotation(), board.FEN())
+				}
+				if cpy.FEN() != board.FEN() {
+					t.Fatalf("IsLegal(%s) changed the position %s\n", m.MiniNotation(), cpy.FEN())
+				}
+			}
+
+			board.MakeLegalMove(mlist.Moves[rnd.Intn(int(mlist.Size))])
+		}
+	}
+}
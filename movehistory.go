@@ -1,26 +1,81 @@
 package chesskimo
 
-// TODO make this variable in size
-
-const (
-	max_history_moves = 1024
-)
-
+// MoveHistory records the Zobrist keys of all positions that were on the board
+// before the current one. The first keys belong to the game, the keys added
+// after StartSearch belong to the path of a running search.
 type MoveHistory struct {
-	size  uint16
-	moves [max_history_moves]Move
+	keys []uint64
+	// root is the number of keys that belong to the game.
+	root int
 }
 
+// Reset removes all keys.
 func (mh *MoveHistory) Reset() {
-	mh.size = 0
+	mh.keys = mh.keys[:0]
+	mh.root = 0
+}
+
+// Push records the key of the position before a move is made.
+func (mh *MoveHistory) Push(key uint64) {
+	mh.keys = append(mh.keys, key)
+}
+
+// Pop removes the last key after a move was taken back.
+func (mh *MoveHistory) Pop() {
+	mh.keys = mh.keys[:len(mh.keys)-1]
+}
+
+// Len returns the number of recorded positions.
+func (mh *MoveHistory) Len() int {
+	return len(mh.keys)
+}
+
+// Copy returns an independent copy of the history with room for a search path.
+func (mh *MoveHistory) Copy() MoveHistory {
+	keys := make([]uint64, len(mh.keys), len(mh.keys)+MAX_PLY)
+	copy(keys, mh.keys)
+	return MoveHistory{keys: keys, root: mh.root}
+}
+
+// StartSearch marks all recorded keys as part of the game. Keys pushed
+// afterwards belong to the search path.
+func (mh *MoveHistory) StartSearch() {
+	mh.root = len(mh.keys)
+}
+
+// repetitions counts how often the position of b occurred before. Only positions
+// with the same player to move since the last capture or pawn move can be equal.
+// inSearch is true if one of the occurrences is on the search path.
+func (mh *MoveHistory) repetitions(b *Board) (count int, inSearch bool) {
+	n := len(mh.keys)
+	for d := 2; d <= int(b.DrawCounter) && d <= n; d += 2 {
+		if mh.keys[n-d] == b.Hash {
+			count++
+			if n-d >= mh.root {
+				inSearch = true
+			}
+		}
+	}
+	return count, inSearch
+}
+
+// IsRepetition returns true if the position of b occurred at least once before.
+func (mh *MoveHistory) IsRepetition(b *Board) bool {
+	count, _ := mh.repetitions(b)
+	return count > 0
 }
 
-func (mh *MoveHistory) Put(m Move) {
-	// TODO -- should we handle excess of 512 moves?
-	mh.moves[mh.size] = m
-	mh.size++
+// IsThreefold returns true if the position of b occurs for (at least) the third time.
+func (mh *MoveHistory) IsThreefold(b *Board) bool {
+	count, _ := mh.repetitions(b)
+	return count >= 2
 }
 
-func (mh *MoveHistory) Get(n uint16) Move {
-	return mh.moves[n]
+// IsSearchDraw returns true if a search should score the position of b as draw.
+// That is the case if it repeats a position of the search path (a twofold
+// repetition, the side to move could repeat again) or if it is a threefold
+// repetition of the game.
+func (mh *MoveHistory) IsSearchDraw(b *Board) bool {
+	count, inSearch := mh.repetitions(b)
+	return inSearch || count >= 2
 }
@@ -0,0 +1,79 @@
+package chesskimo
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestRepetition tests the detection of repeated positions in a game.
+func TestRepetition(t *testing.T) {
+	shuffle := strings.Fields("g1f3 g8f6 f3g1 f6g8 g1f3 g8f6 f3g1 f6g8")
+	type set struct {
+		Moves      []string
+		Repetition bool
+		Threefold  bool
+	}
+	testsets := []set{
+		{shuffle[:3], false, false},
+		{shuffle[:4], true, false},
+		{shuffle[:7], true, false},
+		{shuffle, true, true},
+		// A pawn move makes all earlier positions unreachable, so this is only the second occurrence.
+		{append(shuffle[:4:4], "e2e4", "e7e5", "g1f3", "g8f6", "f3g1", "f6g8", "g1f3"), true, false},
+		// The same squares with different castling rights are different positions.
+		{strings.Fields("e2e4 e7e5 e1e2 e8e7 e2e1 e7e8 e1e2 e8e7 e2e1 e7e8"), true, false},
+	}
+
+	for i, ts := range testsets {
+		engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+		if err := engine.SetPosition("", ts.Moves); err != nil {
+			t.Fatalf("Test %d: %s\n", i, err)
+		}
+		if rep := engine.history.IsRepetition(&engine.board); rep != ts.Repetition {
+			t.Fatalf("Test %d: expected repetition %t but got %t\n", i, ts.Repetition, rep)
+		}
+		if rep := engine.IsThreefoldRepetition(); rep != ts.Threefold {
+			t.Fatalf("Test %d: expected threefold repetition %t but got %t\n", i, ts.Threefold, rep)
+		}
+	}
+}
+
+// TestSearchDraw tests that a single repetition is a draw only on the search path.
+func TestSearchDraw(t *testing.T) {
+	board := NewBoard()
+	mh := MoveHistory{}
+	moves := []BitMove{
+		NewBitMove(0x06, 0x25, NONE), // g1f3
+		NewBitMove(0x76, 0x55, NONE), // g8f6
+		NewBitMove(0x25, 0x06, NONE), // f3g1
+		NewBitMove(0x55, 0x76, NONE), // f6g8
+	}
+
+	// The repetition happened in the game.
+	for _, m := range moves {
+		mh.Push(board.Hash)
+		board.MakeLegalMove(m)
+	}
+	mh.StartSearch()
+	if mh.IsSearchDraw(&board) {
+		t.Fatalf("A twofold repetition of the game must not be a draw\n")
+	}
+
+	// The repetition happens in the search.
+	for _, m := range moves {
+		mh.Push(board.Hash)
+		board.MakeLegalMove(m)
+	}
+	if !mh.IsSearchDraw(&board) {
+		t.Fatalf("A repetition on the search path must be a draw\n")
+	}
+	if !mh.IsThreefold(&board) {
+		t.Fatalf("Expected a threefold repetition\n")
+	}
+	for range moves {
+		mh.Pop()
+	}
+	if mh.Len() != len(moves) {
+		t.Fatalf("Expected %d keys but got %d\n", len(moves), mh.Len())
+	}
+}
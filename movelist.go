@@ -10,6 +10,8 @@ const (
 type MoveList struct {
 	Size  uint32
 	Moves [256]BitMove
+	// Scores contains the ordering score of every move.
+	Scores [256]int32
 }
 
 func (ml *MoveList) Clear() {
@@ -0,0 +1,128 @@
+package chesskimo
+
+// Stages of the MovePicker in the order they are run.
+const (
+	stage_tt_move = iota
+	stage_gen_captures
+	stage_good_captures
+	stage_refutations
+	stage_gen_quiets
+	stage_quiets
+	stage_bad_captures
+	stage_done
+)
+
+// MovePicker yields the legal moves of a position one by one in stages: the hash move,
+// the good captures, the killer and counter moves, the quiet moves and finally the
+// bad captures. A stage only generates its moves when the previous stage is exhausted,
+// so a node that cuts off early does not pay for generating all moves.
+type MovePicker struct {
+	board  *Board
+	order  *MoveOrderer
+	ttMove BitMove
+	ply    int
+	stage  int
+	// refutations are the killer moves and the counter move, in this order.
+	refutations [3]BitMove
+	refIdx      int
+
+	captures MoveList
+	quiets   MoveList
+	// idx and qidx are the indexes of the next capture and the next quiet move.
+	idx  uint32
+	qidx uint32
+}
+
+// NewMovePicker creates a picker for the current position of b. ttMove is the best
+// move of a previous search and prev the move that led to the position. Both may be BitMove(0).
+func NewMovePicker(b *Board, order *MoveOrderer, ttMove BitMove, ply int, prev BitMove) MovePicker {
+	mp := MovePicker{board: b, order: order, ttMove: ttMove, ply: ply}
+	mp.refutations = [3]BitMove{order.killers[ply][0], order.killers[ply][1], order.counterMove(b, prev)}
+	return mp
+}
+
+// Next returns the next move or BitMove(0) if there are no moves left.
+// The position must be the same whenever Next is called.
+func (mp *MovePicker) Next() BitMove {
+	for {
+		switch mp.stage {
+		case stage_tt_move:
+			mp.stage++
+			if mp.ttMove != BitMove(0) && mp.board.IsLegal(mp.ttMove) {
+				return mp.ttMove
+			}
+
+		case stage_gen_captures:
+			mp.board.GenerateCaptures(&mp.captures)
+			mp.order.ScoreMoves(mp.board, &mp.captures, BitMove(0), mp.ply, BitMove(0))
+			mp.idx = 0
+			mp.stage++
+
+		case stage_good_captures:
+			for mp.idx < mp.captures.Size {
+				mp.captures.PickNext(mp.idx)
+				if mp.captures.Scores[mp.idx] < ORDER_GOOD_CAPTURE {
+					// Only bad captures are left. They are searched last.
+					break
+				}
+				m := mp.captures.Moves[mp.idx]
+				mp.idx++
+				if m != mp.ttMove {
+					return m
+				}
+			}
+			mp.stage++
+
+		case stage_refutations:
+			for mp.refIdx < len(mp.refutations) {
+				m := mp.refutations[mp.refIdx]
+				mp.refIdx++
+				if m != BitMove(0) && m != mp.ttMove && !mp.isDuplicate(m, mp.refIdx-1) && mp.board.IsLegal(m) && captureGain(mp.board, m) == 0 {
+					return m
+				}
+			}
+			mp.stage++
+
+		case stage_gen_quiets:
+			mp.board.GenerateQuiets(&mp.quiets)
+			mp.order.ScoreMoves(mp.board, &mp.quiets, BitMove(0), mp.ply, BitMove(0))
+			mp.stage++
+
+		case stage_quiets:
+			for mp.qidx < mp.quiets.Size {
+				mp.quiets.PickNext(mp.qidx)
+				m := mp.quiets.Moves[mp.qidx]
+				mp.qidx++
+				if m != mp.ttMove && !mp.isDuplicate(m, len(mp.refutations)) {
+					return m
+				}
+			}
+			mp.stage++
+
+		case stage_bad_captures:
+			for mp.idx < mp.captures.Size {
+				mp.captures.PickNext(mp.idx)
+				m := mp.captures.Moves[mp.idx]
+				mp.idx++
+				if m != mp.ttMove {
+					return m
+				}
+			}
+			mp.stage++
+
+		default:
+			return BitMove(0)
+		}
+	}
+}
+
+// isDuplicate returns true if m is one of the first n refutations. Such a move was
+// already returned or was rejected in the refutation stage.
+func (mp *MovePicker) isDuplicate(m BitMove, n int) bool {
+	for i := 0; i < n; i++ {
+		if mp.refutations[i] == m {
+			return true
+		}
+	}
+	return false
+}
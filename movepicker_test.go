@@ -0,0 +1,77 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestMovePicker tests that the picker yields every legal move exactly once,
+// no matter which hash and refutation moves it gets, including illegal ones.
+func TestMovePicker(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+		"rnbqkb1r/pp2pppp/5n2/2ppP3/3P4/8/PPP2PPP/RNBQKBNR w KQkq d6 0 4",
+	}
+
+	// Moves of all positions are used as hash and killer moves in every position.
+	candidates := []BitMove{}
+	board := NewBoard()
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		mlist := MoveList{}
+		board.GenerateAllLegalMoves(&mlist)
+		for i := uint32(0); i < mlist.Size; i += 3 {
+			candidates = append(candidates, mlist.Moves[i])
+		}
+	}
+
+	for _, fen := range fens {
+		board.SetFEN(fen)
+		all := MoveList{}
+		board.GenerateAllLegalMoves(&all)
+		legal := map[BitMove]bool{}
+		for i := uint32(0); i < all.Size; i++ {
+			legal[all.Moves[i]] = true
+		}
+
+		for c, ttMove := range candidates {
+			order := NewMoveOrderer()
+			order.killers[1][0] = candidates[(c+1)%len(candidates)]
+			order.killers[1][1] = candidates[(c+7)%len(candidates)]
+			picker := NewMovePicker(&board, order, ttMove, 1, BitMove(0))
+
+			seen := map[BitMove]bool{}
+			for m := picker.Next(); m != BitMove(0); m = picker.Next() {
+				if !legal[m] {
+					t.Fatalf("Illegal move %s picked in FEN %s\n", m.MiniNotation(), fen)
+				}
+				if seen[m] {
+					t.Fatalf("Move %s picked twice in FEN %s\n", m.MiniNotation(), fen)
+				}
+				if len(seen) == 0 && legal[ttMove] && m != ttMove {
+					t.Fatalf("Expected hash move %s first in FEN %s but got %s\n", ttMove.MiniNotation(), fen, m.MiniNotation())
+				}
+				seen[m] = true
+			}
+			if len(seen) != len(legal) {
+				t.Fatalf("Picked %d moves but there are %d legal moves in FEN %s\n", len(seen), len(legal), fen)
+			}
+		}
+	}
+}
+
+// TestMovePickerIsLazy tests that no moves are generated before the hash move is searched.
+func TestMovePickerIsLazy(t *testing.T) {
+	board := NewBoard()
+	ttMove, _ := board.ParseMove("e2e4")
+	picker := NewMovePicker(&board, NewMoveOrderer(), ttMove, 1, BitMove(0))
+	if m := picker.Next(); m != ttMove {
+		t.Fatalf("Expected hash move e2e4 but got %s\n", m.MiniNotation())
+	}
+	if picker.captures.Size != 0 || picker.quiets.Size != 0 {
+		t.Fatalf("Expected no generated moves after the hash move\n")
+	}
+}
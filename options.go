@@ -0,0 +1,229 @@
+package chesskimo
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// OptionType defines which values an option accepts. The names follow the UCI protocol.
+type OptionType uint8
+
+const (
+	OPTION_CHECK OptionType = iota
+	OPTION_SPIN
+	OPTION_COMBO
+	OPTION_BUTTON
+	OPTION_STRING
+
+	// MAX_MULTIPV is the highest number of principal variations a search reports.
+	MAX_MULTIPV = 64
+)
+
+var (
+	// ErrUnknownOption is returned when an option does not exist.
+	ErrUnknownOption = errors.New("Option does not exist")
+	// ErrInvalidOptionValue is returned when a value does not fit the type or the bounds of an option.
+	ErrInvalidOptionValue = errors.New("Option value is invalid")
+
+	optionTypeNames = [...]string{
+		OPTION_CHECK:  "check",
+		OPTION_SPIN:   "spin",
+		OPTION_COMBO:  "combo",
+		OPTION_BUTTON: "button",
+		OPTION_STRING: "string",
+	}
+)
+
+func (t OptionType) String() string {
+	return optionTypeNames[t]
+}
+
+// Option is a setting of the engine that the frontend may change.
+// Values are kept as strings in their normalized form, e.g. "true" for a check option.
+type Option struct {
+	Name    string
+	Type    OptionType
+	Default string
+	// Min and Max are the bounds of a spin option.
+	Min, Max int
+	// Vars are the allowed values of a combo option.
+	Vars []string
+
+	value string
+	// apply changes the engine according to a validated value. It may be nil.
+	apply func(e *Engine, value string) error
+}
+
+// newOptions declares all options of the engine with their default values.
+// The default of the Search option is the search the engine is created with.
+func newOptions(search NamedSearch) []*Option {
+	options := []*Option{
+		{
+			Name: "Hash", Type: OPTION_SPIN, Default: strconv.Itoa(TT_DEFAULT_MB), Min: TT_MIN_MB, Max: TT_MAX_MB,
+			apply: func(e *Engine, value string) error {
+				mb, _ := strconv.Atoi(value)
+				e.tt.Resize(mb)
+				return nil
+			},
+		},
+		{
+			Name: "Clear Hash", Type: OPTION_BUTTON,
+			apply: func(e *Engine, value string) error {
+				e.tt.Clear()
+				return nil
+			},
+		},
+		{
+			// The search is single-threaded (for now).
+			Name: "Threads", Type: OPTION_SPIN, Default: "1", Min: 1, Max: 1,
+		},
+		{
+			Name: "MultiPV", Type: OPTION_SPIN, Default: "1", Min: 1, Max: MAX_MULTIPV,
+			apply: func(e *Engine, value string) error {
+				e.multiPV, _ = strconv.Atoi(value)
+				return nil
+			},
+		},
+		{
+			// The option only tells the engine that the frontend may use pondering.
+			Name: "Ponder", Type: OPTION_CHECK, Default: "false",
+		},
+		searchOption(search),
+		{
+			// An empty path disables logging.
+			Name: "LogFile", Type: OPTION_STRING, Default: "chesskimo.log",
+			apply: func(e *Engine, value string) error {
+				return e.setLogFile(value)
+			},
+		},
+		{
+			// Every game is appended to this file when a new game starts. An empty path disables it.
+			Name: "PGNFile", Type: OPTION_STRING, Default: "",
+			apply: func(e *Engine, value string) error {
+				e.pgnFile = value
+				return nil
+			},
+		},
+		selectivityOption("NullMove", func(s *Selectivity) *bool { return &s.NullMove }),
+		selectivityOption("LMR", func(s *Selectivity) *bool { return &s.LMR }),
+		selectivityOption("ReverseFutility", func(s *Selectivity) *bool { return &s.ReverseFutility }),
+		selectivityOption("Futility", func(s *Selectivity) *bool { return &s.Futility }),
+		selectivityOption("LateMovePruning", func(s *Selectivity) *bool { return &s.LateMovePruning }),
+	}
+
+	for _, o := range options {
+		o.value = o.Default
+	}
+	return options
+}
+
+// searchOption declares the combo option that selects the search function. It offers
+// the searches of the engine and 'search', which is the default.
+func searchOption(search NamedSearch) *Option {
+	searches := []NamedSearch{ALPHA_BETA_SEARCH, MONTE_CARLO_SEARCH}
+	known := false
+	for _, ns := range searches {
+		known = known || ns.Name == search.Name
+	}
+	if !known {
+		searches = append(searches, search)
+	}
+
+	o := &Option{
+		Name: "Search", Type: OPTION_COMBO, Default: search.Name,
+		apply: func(e *Engine, value string) error {
+			for _, ns := range searches {
+				if ns.Name == value {
+					e.search = ns.Fun
+					return nil
+				}
+			}
+			return ErrInvalidOptionValue
+		},
+	}
+	for _, ns := range searches {
+		o.Vars = append(o.Vars, ns.Name)
+	}
+	return o
+}
+
+// selectivityOption declares a check option that switches one technique of Selectivity.
+func selectivityOption(name string, flag func(s *Selectivity) *bool) *Option {
+	return &Option{
+		Name: name, Type: OPTION_CHECK, Default: "true",
+		apply: func(e *Engine, value string) error {
+			*flag(&e.selectivity) = value == "true"
+			return nil
+		},
+	}
+}
+
+// Value returns the current value of the option.
+func (o *Option) Value() string {
+	return o.value
+}
+
+// UCIString returns the declaration of the option for the 'uci' command.
+func (o *Option) UCIString() string {
+	var sb strings.Builder
+	sb.WriteString("option name " + o.Name + " type " + o.Type.String())
+
+	switch o.Type {
+	case OPTION_CHECK:
+		sb.WriteString(" default " + o.Default)
+	case OPTION_SPIN:
+		sb.WriteString(" default " + o.Default)
+		sb.WriteString(" min " + strconv.Itoa(o.Min) + " max " + strconv.Itoa(o.Max))
+	case OPTION_COMBO:
+		sb.WriteString(" default " + o.Default)
+		for _, v := range o.Vars {
+			sb.WriteString(" var " + v)
+		}
+	case OPTION_STRING:
+		def := o.Default
+		if def == "" {
+			def = "<empty>"
+		}
+		sb.WriteString(" default " + def)
+	}
+
+	return sb.String()
+}
+
+// normalize checks value against the type and the bounds of the option
+// and returns it in the form it is stored.
+func (o *Option) normalize(value string) (string, error) {
+	value = strings.TrimSpace(value)
+
+	switch o.Type {
+	case OPTION_CHECK:
+		switch strings.ToLower(value) {
+		case "true":
+			return "true", nil
+		case "false":
+			return "false", nil
+		}
+	case OPTION_SPIN:
+		n, err := strconv.Atoi(value)
+		if err == nil && n >= o.Min && n <= o.Max {
+			return strconv.Itoa(n), nil
+		}
+	case OPTION_COMBO:
+		for _, v := range o.Vars {
+			if strings.EqualFold(v, value) {
+				return v, nil
+			}
+		}
+	case OPTION_BUTTON:
+		// Buttons have no value.
+		return "", nil
+	case OPTION_STRING:
+		if value == "<empty>" {
+			return "", nil
+		}
+		return value, nil
+	}
+
+	return "", ErrInvalidOptionValue
+}
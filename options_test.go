@@ -0,0 +1,84 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestSetOption tests the validation and application of option values.
+func TestSetOption(t *testing.T) {
+	type set struct {
+		Name  string
+		Value string
+		Err   error
+		// Expected value of the option afterwards.
+		Result string
+	}
+	testsets := []set{
+		{"Hash", "32", nil, "32"},
+		{"hash", "0", ErrInvalidOptionValue, "32"},
+		{"Hash", "big", ErrInvalidOptionValue, "32"},
+		{"Threads", "2", ErrInvalidOptionValue, "1"},
+		{"MultiPV", "3", nil, "3"},
+		{"Ponder", "TRUE", nil, "true"},
+		{"Ponder", "yes", ErrInvalidOptionValue, "true"},
+		{"Search", "montecarlo", nil, "MonteCarlo"},
+		{"Search", "Random", ErrInvalidOptionValue, "MonteCarlo"},
+		{"LogFile", "<empty>", nil, ""},
+		{"Clear Hash", "", nil, ""},
+		{"Contempt", "10", ErrUnknownOption, ""},
+	}
+
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	for i, ts := range testsets {
+		err := engine.SetOption(ts.Name, ts.Value)
+		if err != ts.Err {
+			t.Fatalf("Test %d: expected error %v but got %v\n", i, ts.Err, err)
+		}
+		if o := engine.Option(ts.Name); o != nil && o.Value() != ts.Result {
+			t.Fatalf("Test %d: expected value %q but got %q\n", i, ts.Result, o.Value())
+		}
+	}
+
+	if engine.multiPV != 3 {
+		t.Fatalf("Expected MultiPV 3 but got %d\n", engine.multiPV)
+	}
+	if len(engine.tt.buckets) != len(NewTranspositionTable(32).buckets) {
+		t.Fatalf("Expected the transposition table to be resized\n")
+	}
+}
+
+// TestOptionUCIString tests the declarations of the options for the 'uci' command.
+func TestOptionUCIString(t *testing.T) {
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	expected := map[string]string{
+		"Hash":       "option name Hash type spin default 16 min 1 max 4096",
+		"Clear Hash": "option name Clear Hash type button",
+		"Ponder":     "option name Ponder type check default false",
+		"Search":     "option name Search type combo default AlphaBeta var AlphaBeta var MonteCarlo",
+		"LogFile":    "option name LogFile type string default chesskimo.log",
+		"PGNFile":    "option name PGNFile type string default <empty>",
+		"NullMove":   "option name NullMove type check default true",
+	}
+
+	for name, exp := range expected {
+		if got := engine.Option(name).UCIString(); got != exp {
+			t.Fatalf("Expected '%s' but got '%s'\n", exp, got)
+		}
+	}
+
+	// The Search option follows the search function of the engine.
+	engine = NewEngine("test", "test", nil, MONTE_CARLO_SEARCH)
+	exp := "option name Search type combo default MonteCarlo var AlphaBeta var MonteCarlo"
+	if got := engine.Option("Search").UCIString(); got != exp || engine.Option("Search").Value() != "MonteCarlo" {
+		t.Fatalf("Expected '%s' with value MonteCarlo but got '%s' with value %s\n", exp, got, engine.Option("Search").Value())
+	}
+	// A search the option does not know is offered as well.
+	engine = NewEngine("test", "test", nil, NamedSearch{Name: "Custom", Fun: SimpleMCSearch})
+	exp = "option name Search type combo default Custom var AlphaBeta var MonteCarlo var Custom"
+	if got := engine.Option("Search").UCIString(); got != exp {
+		t.Fatalf("Expected '%s' but got '%s'\n", exp, got)
+	}
+	if err := engine.Option("Search").apply(engine, "Random"); err != ErrInvalidOptionValue {
+		t.Fatalf("Expected error %v for an unknown search but got %v\n", ErrInvalidOptionValue, err)
+	}
+}
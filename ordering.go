@@ -0,0 +1,133 @@
+package chesskimo
+
+const (
+	// Ordering scores of the move classes. Every class is searched before the next one.
+	ORDER_HASH_MOVE    = 1 << 30
+	ORDER_GOOD_CAPTURE = 1 << 24
+	ORDER_KILLER_1     = 1 << 22
+	ORDER_KILLER_2     = ORDER_KILLER_1 - 1
+	ORDER_COUNTER_MOVE = ORDER_KILLER_1 - 2
+	// Quiet moves are ordered by their history score in [-HISTORY_MAX, HISTORY_MAX].
+	HISTORY_MAX          = 1 << 16
+	ORDER_BAD_CAPTURE    = -(1 << 24)
+	ORDER_UNDERPROMOTION = -(1 << 25)
+)
+
+// MoveOrderer ranks the moves of a search. It learns from the beta cutoffs of quiet moves:
+// the killer moves of every ply, the counter move to every previous move and a butterfly
+// history table that counts how often a move of a color from one square to another cut off.
+type MoveOrderer struct {
+	killers [MAX_PLY + 1][2]BitMove
+	// counters is indexed by the piece and the target square of the previous move.
+	counters [KING | WHITE + 1][128]BitMove
+	history  [2][128][128]int
+}
+
+// NewMoveOrderer creates an orderer with empty tables.
+func NewMoveOrderer() *MoveOrderer {
+	return &MoveOrderer{}
+}
+
+// ScoreMoves assigns an ordering score to every move of the list. prev is the move that
+// led to the position or BitMove(0) if there is none.
+func (mo *MoveOrderer) ScoreMoves(b *Board, mlist *MoveList, ttMove BitMove, ply int, prev BitMove) {
+	counter := mo.counterMove(b, prev)
+	for i := uint32(0); i < mlist.Size; i++ {
+		m := mlist.Moves[i]
+		score := 0
+		switch promo := m.PromotedPiece(); {
+		case m == ttMove:
+			score = ORDER_HASH_MOVE
+		case promo != NONE && promo != QUEEN:
+			score = ORDER_UNDERPROMOTION + mvvLva(b, m)
+		case captureGain(b, m) > 0:
+			if b.SEE(m) >= 0 {
+				score = ORDER_GOOD_CAPTURE + mvvLva(b, m)
+			} else {
+				score = ORDER_BAD_CAPTURE + mvvLva(b, m)
+			}
+		case m == mo.killers[ply][0]:
+			score = ORDER_KILLER_1
+		case m == mo.killers[ply][1]:
+			score = ORDER_KILLER_2
+		case m == counter:
+			score = ORDER_COUNTER_MOVE
+		default:
+			score = mo.history[b.Player][m.From()][m.To()]
+		}
+		mlist.Scores[i] = int32(score)
+	}
+}
+
+// Update rewards the quiet move m that caused a beta cutoff at the given depth and
+// punishes the quiet moves that were searched before it without a cutoff.
+func (mo *MoveOrderer) Update(b *Board, m BitMove, tried []BitMove, depth, ply int, prev BitMove) {
+	if mo.killers[ply][0] != m {
+		mo.killers[ply][1] = mo.killers[ply][0]
+		mo.killers[ply][0] = m
+	}
+	if prev != BitMove(0) {
+		mo.counters[b.Squares[prev.To()]][prev.To()] = m
+	}
+
+	bonus := depth * depth
+	mo.addHistory(b.Player, m, bonus)
+	for _, t := range tried {
+		mo.addHistory(b.Player, t, -bonus)
+	}
+}
+
+// addHistory changes the history score of a move. The scores stay within
+// [-HISTORY_MAX, HISTORY_MAX] because large scores are dampened.
+func (mo *MoveOrderer) addHistory(color Color, m BitMove, bonus int) {
+	entry := &mo.history[color][m.From()][m.To()]
+	abs := bonus
+	if abs < 0 {
+		abs = -abs
+	}
+	*entry += bonus - *entry*abs/HISTORY_MAX
+}
+
+// counterMove returns the move that last refuted prev or BitMove(0).
+func (mo *MoveOrderer) counterMove(b *Board, prev BitMove) BitMove {
+	if prev == BitMove(0) {
+		return BitMove(0)
+	}
+	return mo.counters[b.Squares[prev.To()]][prev.To()]
+}
+
+// captureGain returns the material the move wins: the value of the captured
+// piece plus the value a promotion adds to the pawn. It is 0 for quiet moves.
+func captureGain(b *Board, m BitMove) int {
+	from, to, promo := m.All()
+	gain := 0
+	if target := b.Squares[to]; target != EMPTY {
+		gain = MaterialValues[target&PIECE_MASK]
+	} else if to == b.EpSquare && b.Squares[from]&PIECE_MASK == PAWN {
+		gain = MaterialValues[PAWN]
+	}
+	if promo != NONE {
+		gain += MaterialValues[promo] - MaterialValues[PAWN]
+	}
+	return gain
+}
+
+// mvvLva scores a capture by 'most valuable victim - least valuable attacker':
+// captures of valuable pieces come first and among them the ones with the cheapest attacker.
+func mvvLva(b *Board, m BitMove) int {
+	attacker := b.Squares[m.From()] & PIECE_MASK
+	return captureGain(b, m)*8 - MaterialValues[attacker]/100
+}
+
+// PickNext moves the best scored move of the moves from index i on to index i.
+// Picking the moves one by one is cheaper than sorting when a node cuts off early.
+func (ml *MoveList) PickNext(i uint32) {
+	best := i
+	for j := i + 1; j < ml.Size; j++ {
+		if ml.Scores[j] > ml.Scores[best] {
+			best = j
+		}
+	}
+	ml.Moves[i], ml.Moves[best] = ml.Moves[best], ml.Moves[i]
+	ml.Scores[i], ml.Scores[best] = ml.Scores[best], ml.Scores[i]
+}
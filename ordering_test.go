@@ -0,0 +1,80 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestScoreMoves tests the order of the move classes.
+func TestScoreMoves(t *testing.T) {
+	board := NewBoard()
+	// Kiwipete: white has good captures (e.g. d5e6), bad captures (e.g. f3f6 loses the queen) and quiet moves.
+	if err := board.SetFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"); err != nil {
+		t.Fatalf(err.Error())
+	}
+	parse := func(s string) BitMove {
+		m, err := board.ParseMove(s)
+		if err != nil {
+			t.Fatalf("Move %s: %s\n", s, err)
+		}
+		return m
+	}
+	ttMove, killer, counter := parse("a2a3"), parse("e1g1"), parse("b2b3")
+	// The previous move of black was g7g6.
+	prev := NewBitMove(0x66, 0x56, NONE)
+
+	mo := NewMoveOrderer()
+	mo.Update(&board, killer, nil, 4, 2, BitMove(0))
+	// The counter move is stored for the piece on the target square of the previous move.
+	mo.counters[board.Squares[prev.To()]][prev.To()] = counter
+
+	mlist := MoveList{}
+	board.GenerateAllLegalMoves(&mlist)
+	mo.ScoreMoves(&board, &mlist, ttMove, 2, prev)
+	for i := uint32(0); i < mlist.Size; i++ {
+		mlist.PickNext(i)
+		if i > 0 && mlist.Scores[i] > mlist.Scores[i-1] {
+			t.Fatalf("Moves are not picked in order: %s\n", mlist.String())
+		}
+	}
+
+	order := map[BitMove]uint32{}
+	for i := uint32(0); i < mlist.Size; i++ {
+		order[mlist.Moves[i]] = i
+	}
+	if order[ttMove] != 0 {
+		t.Fatalf("Expected the hash move first but got %s\n", mlist.String())
+	}
+	good, bad := parse("d5e6"), parse("f3f6")
+	if !(order[good] < order[killer] && order[killer] < order[counter] && order[counter] < order[bad]) {
+		t.Fatalf("Expected good capture < killer < counter move < bad capture but got %s\n", mlist.String())
+	}
+}
+
+// TestFirstCutoffRate tests that the move ordering causes most cutoffs with the first move.
+func TestFirstCutoffRate(t *testing.T) {
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	if err := engine.board.SetFEN("r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1"); err != nil {
+		t.Fatalf(err.Error())
+	}
+	dostop := uint32(0)
+	ss := SearchSettings{}
+	s := abSearch{
+		engine:  engine,
+		board:   engine.board,
+		history: engine.history.Copy(),
+		tt:      engine.tt,
+		dostop:  &dostop,
+		tm:      engine.newTimeManager(&ss),
+		ss:      &ss,
+		order:   NewMoveOrderer(),
+	}
+
+	mlist := MoveList{}
+	s.board.GenerateAllLegalMoves(&mlist)
+	for depth := 1; depth <= 4; depth++ {
+		s.searchRoot(&mlist, depth, 0, -INFINITY, INFINITY)
+	}
+	if rate := s.firstCutoffRate(); rate < 0.9 {
+		t.Fatalf("Expected at least 90%% of the cutoffs on the first move but got %.1f%%\n", rate*100)
+	}
+}
@@ -0,0 +1,84 @@
+package chesskimo
+
+import (
+	"errors"
+)
+
+var (
+	// ErrMoveUnknownSquare is returned if a square of a move does not exist.
+	ErrMoveUnknownSquare = errors.New("Move has an unknown square")
+	// ErrMoveNoPiece is returned if a move does not start on a piece of the player to move.
+	ErrMoveNoPiece = errors.New("Move does not start on a piece of the player to move")
+	// ErrMoveIllegal is returned if a move is not legal in the current position.
+	ErrMoveIllegal = errors.New("Move is illegal")
+	// ErrMoveMissingPromotion is returned if a pawn reaches the last rank without a promotion piece.
+	ErrMoveMissingPromotion = errors.New("Move is missing the promotion piece")
+)
+
+// ParseMove parses a move in coordinate notation (e.g. 'e2e4' or 'e7e8q') and
+// returns it, if it is legal in the current position. Castling may also be
+// given as king captures own rook (e.g. 'e1h1').
+func (b *Board) ParseMove(move string) (BitMove, error) {
+	if len(move) != 4 && len(move) != 5 {
+		return BitMove(0), ErrInvalidMoveNotation
+	}
+
+	from, err := parseMoveSquare(move[0:2])
+	if err != nil {
+		return BitMove(0), err
+	}
+	to, err := parseMoveSquare(move[2:4])
+	if err != nil {
+		return BitMove(0), err
+	}
+
+	promo := NONE
+	if len(move) == 5 {
+		switch move[4] {
+		case 'q', 'Q':
+			promo = QUEEN
+		case 'r', 'R':
+			promo = ROOK
+		case 'b', 'B':
+			promo = BISHOP
+		case 'n', 'N':
+			promo = KNIGHT
+		default:
+			// Impossible promotion.
+			return BitMove(0), ErrInvalidMoveNotation
+		}
+	}
+
+	piece := b.Squares[from]
+	if !piece.HasColor(b.Player) {
+		return BitMove(0), ErrMoveNoPiece
+	}
+
+	if piece == KING|b.Player && b.Squares[to] == ROOK|b.Player && promo == NONE {
+		// King takes own rook means castling to that side.
+		if to.File() > from.File() {
+			to = CASTLING_PATH_SHORT[b.Player][1]
+		} else {
+			to = CASTLING_PATH_LONG[b.Player][1]
+		}
+	}
+
+	bm := NewBitMove(from, to, promo)
+	if b.IsLegal(bm) {
+		return bm, nil
+	}
+	if promo == NONE && piece == PAWN|b.Player && to.IsPawnPromoting(b.Player) && b.IsLegal(NewBitMove(from, to, QUEEN)) {
+		return BitMove(0), ErrMoveMissingPromotion
+	}
+	return BitMove(0), ErrMoveIllegal
+}
+
+// parseMoveSquare converts a square like 'e4' to its 0x88 index.
+func parseMoveSquare(sq string) (Square, error) {
+	file, rank := sq[0], sq[1]
+	if file < 'a' || file > 'h' || rank < '1' || rank > '8' {
+		return OTB, ErrMoveUnknownSquare
+	}
+
+	return Square(rank-'1')*16 + Square(file-'a'), nil
+}
@@ -0,0 +1,61 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestParseMove tests the parsing and legality check of coordinate moves.
+func TestParseMove(t *testing.T) {
+	type set struct {
+		FEN  string
+		Move string
+		Err  error
+		// Expected move in mini notation.
+		Result string
+	}
+	start := "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1"
+	castling := "r3k2r/8/8/8/8/8/8/R3K2R w KQkq - 0 1"
+	promotion := "8/4P1k1/8/8/8/8/8/4K3 w - - 0 1"
+	testsets := []set{
+		{start, "e2e4", nil, "e2e4"},
+		{start, "g1f3", nil, "g1f3"},
+		{start, "e2e5", ErrMoveIllegal, ""},
+		{start, "e7e5", ErrMoveNoPiece, ""},
+		{start, "e3e4", ErrMoveNoPiece, ""},
+		{start, "i2i4", ErrMoveUnknownSquare, ""},
+		{start, "e2e9", ErrMoveUnknownSquare, ""},
+		{start, "e2", ErrInvalidMoveNotation, ""},
+		{start, "e2e4k", ErrInvalidMoveNotation, ""},
+		{castling, "e1g1", nil, "e1g1"},
+		{castling, "e1h1", nil, "e1g1"},
+		{castling, "e1a1", nil, "e1c1"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R b KQkq - 0 1", "e8h8", nil, "e8g8"},
+		{"r3k2r/8/8/8/8/8/8/R3K2R w Qkq - 0 1", "e1h1", ErrMoveIllegal, ""},
+		// The king must not move into check.
+		{"8/8/8/8/8/3k4/8/4K3 w - - 0 1", "e1e2", ErrMoveIllegal, ""},
+		{"8/8/8/8/8/3k4/8/4K3 w - - 0 1", "e1f1", nil, "e1f1"},
+		{promotion, "e7e8q", nil, "e7e8q"},
+		{promotion, "e7e8N", nil, "e7e8n"},
+		{promotion, "e7e8", ErrMoveMissingPromotion, ""},
+		{start, "e2e4q", ErrMoveIllegal, ""},
+	}
+
+	for i, ts := range testsets {
+		board := NewBoard()
+		if err := board.SetFEN(ts.FEN); err != nil {
+			t.Fatalf("Test %d: invalid FEN: %s\n", i, err)
+		}
+		before := board.FEN()
+
+		move, err := board.ParseMove(ts.Move)
+		if err != ts.Err {
+			t.Fatalf("Test %d: expected error %v but got %v\n", i, ts.Err, err)
+		}
+		if err == nil && move.MiniNotation() != ts.Result {
+			t.Fatalf("Test %d: expected move %s but got %s\n", i, ts.Result, move.MiniNotation())
+		}
+		if board.FEN() != before {
+			t.Fatalf("Test %d: parsing changed the board\n", i)
+		}
+	}
+}
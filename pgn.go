@@ -0,0 +1,529 @@
+package chesskimo
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const (
+	// Token types of the PGN scanner.
+	pgn_token_symbol = iota
+	pgn_token_string
+	pgn_token_comment
+	pgn_token_nag
+	pgn_token_tag_open
+	pgn_token_tag_close
+	pgn_token_variation_open
+	pgn_token_variation_close
+	pgn_token_result
+)
+
+var (
+	// ErrPGNSyntax is returned if a PGN file does not follow the PGN grammar.
+	ErrPGNSyntax = errors.New("PGN has invalid syntax")
+	// ErrPGNVariation is returned if a variation does not follow a move.
+	ErrPGNVariation = errors.New("PGN variation has no move to replace")
+
+	// pgnGlyphs maps move suffix annotations to their numeric annotation glyphs (NAGs).
+	pgnGlyphs = map[string]int{
+		"!":  1,
+		"?":  2,
+		"!!": 3,
+		"??": 4,
+		"!?": 5,
+		"?!": 6,
+	}
+)
+
+// PGNTag is a tag pair of a PGN game, e.g. [Event "Casual game"].
+type PGNTag struct {
+	Name  string
+	Value string
+}
+
+// PGNMove is a move of a PGN game with its annotations.
+type PGNMove struct {
+	Move BitMove
+	SAN  string
+	// NAGs are the numeric annotation glyphs of the move. Suffixes like '!?' are converted.
+	NAGs []int
+	// CommentBefore is a comment in front of the move, Comment follows the move.
+	CommentBefore string
+	Comment       string
+	// Variations are alternatives to this move. Each starts in the position before it.
+	Variations [][]PGNMove
+}
+
+// PGNGame is a game read from a PGN file. All moves (including variations)
+// were checked for legality while reading.
+type PGNGame struct {
+	Tags []PGNTag
+	// Start is the position before the first move. It is set by the FEN tag or
+	// is the standard starting position.
+	Start  Board
+	Moves  []PGNMove
+	Result string
+
+	// end is the position after the first endMoves moves, if endValid is set.
+	// AddMove keeps it up to date, so adding a move does not replay the game.
+	end      Board
+	endMoves int
+	endValid bool
+}
+
+// Tag returns the value of the tag with the given name or "" if it does not exist.
+func (g *PGNGame) Tag(name string) string {
+	for _, t := range g.Tags {
+		if t.Name == name {
+			return t.Value
+		}
+	}
+	return ""
+}
+
+// Replay plays the main line of the game. For every move fn is called with the
+// position before the move. Replay stops early if fn returns false.
+// The final position is returned.
+func (g *PGNGame) Replay(fn func(b *Board, m *PGNMove) bool) Board {
+	board := g.Start
+	for i := range g.Moves {
+		if fn != nil && !fn(&board, &g.Moves[i]) {
+			break
+		}
+		board.MakeLegalMove(g.Moves[i].Move)
+	}
+	return board
+}
+
+// pgnToken is a single token of PGN input.
+type pgnToken struct {
+	typ   int
+	value string
+	// afterBlankLine is true if an empty line (or the start of the input) precedes the token.
+	afterBlankLine bool
+}
+
+// PGNReader reads games one by one from PGN input. Only the current game
+// is kept in memory, so files of any size can be read.
+type PGNReader struct {
+	r    *bufio.Reader
+	line int
+	// lineStart is true if the next character starts a new line.
+	lineStart bool
+	// peeked holds the tokens that were read but not consumed. The last one is the next token.
+	peeked []pgnToken
+	// newlines counts the line breaks since the last token.
+	newlines int
+	// blankLine is true if the last token scanned starts after an empty line.
+	blankLine bool
+}
+
+// NewPGNReader creates a reader for the PGN input r.
+func NewPGNReader(r io.Reader) *PGNReader {
+	return &PGNReader{r: bufio.NewReader(r), line: 1, lineStart: true, newlines: 2}
+}
+
+// Next reads the next game. It returns io.EOF if there are no more games.
+// If a game is invalid an error is returned and the rest of that game is
+// skipped, so reading may continue with the next game.
+func (pr *PGNReader) Next() (*PGNGame, error) {
+	if _, err := pr.peek(); err != nil {
+		return nil, err
+	}
+
+	game, err := pr.readGame()
+	if err != nil {
+		line := pr.line
+		pr.skipGame()
+		return nil, fmt.Errorf("pgn line %d: %w", line, err)
+	}
+	return game, nil
+}
+
+// readGame reads the tag pairs and the movetext of one game.
+func (pr *PGNReader) readGame() (*PGNGame, error) {
+	game := &PGNGame{Start: NewBoard()}
+
+	for {
+		tok, err := pr.peek()
+		if err != nil || tok.typ != pgn_token_tag_open {
+			break
+		}
+		pr.next()
+		tag, err := pr.readTag()
+		if err != nil {
+			return nil, err
+		}
+		game.Tags = append(game.Tags, tag)
+	}
+
+	if fen := game.Tag("FEN"); fen != "" && game.Tag("SetUp") != "0" {
+		if err := game.Start.SetFEN(fen); err != nil {
+			return nil, err
+		}
+	}
+
+	moves, err := pr.readMoves(game.Start, game.Start, false)
+	if err != nil {
+		return nil, err
+	}
+	game.Moves = moves
+
+	// The game ends with a result, the next game or the end of the input.
+	game.Result = game.Tag("Result")
+	if tok, err := pr.peek(); err == nil && tok.typ == pgn_token_result {
+		pr.next()
+		game.Result = tok.value
+	}
+	if game.Result == "" {
+		game.Result = "*"
+	}
+
+	return game, nil
+}
+
+// readTag reads a tag pair after its opening bracket.
+func (pr *PGNReader) readTag() (PGNTag, error) {
+	name, err := pr.next()
+	if err != nil || name.typ != pgn_token_symbol {
+		return PGNTag{}, ErrPGNSyntax
+	}
+	value, err := pr.next()
+	if err != nil || value.typ != pgn_token_string {
+		return PGNTag{}, ErrPGNSyntax
+	}
+	end, err := pr.next()
+	if err != nil || end.typ != pgn_token_tag_close {
+		return PGNTag{}, ErrPGNSyntax
+	}
+
+	return PGNTag{Name: name.value, Value: value.value}, nil
+}
+
+// readMoves reads a line of moves starting in position 'board'. 'before' is
+// the position before the last move of the enclosing line, where variations
+// of the first move start. A variation ends with a closing parenthesis,
+// the main line ends with a result, a new game or the end of the input.
+func (pr *PGNReader) readMoves(board, before Board, variation bool) ([]PGNMove, error) {
+	moves := []PGNMove{}
+	comment := ""
+
+	for {
+		tok, err := pr.peek()
+		if err == io.EOF {
+			if variation {
+				return nil, ErrPGNSyntax
+			}
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		if tok.typ == pgn_token_result || tok.typ == pgn_token_tag_open {
+			if variation {
+				return nil, ErrPGNSyntax
+			}
+			break
+		}
+		pr.next()
+
+		switch tok.typ {
+		case pgn_token_variation_close:
+			if !variation {
+				return nil, ErrPGNSyntax
+			}
+			if comment != "" && len(moves) > 0 {
+				moves[len(moves)-1].Comment = joinComments(moves[len(moves)-1].Comment, comment)
+			}
+			return moves, nil
+		case pgn_token_variation_open:
+			if len(moves) == 0 {
+				return nil, ErrPGNVariation
+			}
+			last := &moves[len(moves)-1]
+			if comment != "" {
+				last.Comment = joinComments(last.Comment, comment)
+				comment = ""
+			}
+			vmoves, err := pr.readMoves(before, before, true)
+			if err != nil {
+				return nil, err
+			}
+			last.Variations = append(last.Variations, vmoves)
+		case pgn_token_comment:
+			comment = joinComments(comment, tok.value)
+		case pgn_token_nag:
+			if len(moves) == 0 {
+				return nil, ErrPGNSyntax
+			}
+			nag, err := strconv.Atoi(tok.value)
+			if err != nil {
+				return nil, ErrPGNSyntax
+			}
+			moves[len(moves)-1].NAGs = append(moves[len(moves)-1].NAGs, nag)
+		case pgn_token_symbol:
+			san, glyph := splitPGNSymbol(tok.value)
+			if san == "" {
+				// A move number or a glyph on its own.
+				if nag, ok := pgnGlyphs[glyph]; ok && len(moves) > 0 {
+					moves[len(moves)-1].NAGs = append(moves[len(moves)-1].NAGs, nag)
+				}
+				continue
+			}
+
+			m, err := board.ParseSAN(san)
+			if err != nil {
+				return nil, fmt.Errorf("move %s: %w", san, err)
+			}
+			if len(moves) > 0 && comment != "" {
+				moves[len(moves)-1].Comment = joinComments(moves[len(moves)-1].Comment, comment)
+				comment = ""
+			}
+			pm := PGNMove{Move: m, SAN: board.MoveToSAN(m), CommentBefore: comment}
+			if nag, ok := pgnGlyphs[glyph]; ok {
+				pm.NAGs = append(pm.NAGs, nag)
+			}
+			moves = append(moves, pm)
+			comment = ""
+
+			before = board
+			board.MakeLegalMove(m)
+		default:
+			return nil, ErrPGNSyntax
+		}
+	}
+
+	if comment != "" && len(moves) > 0 {
+		moves[len(moves)-1].Comment = joinComments(moves[len(moves)-1].Comment, comment)
+	}
+	return moves, nil
+}
+
+// skipGame discards tokens until the end of the current game: the result that ends its
+// movetext or the Event tag of the next game after an empty line. Other tags may still
+// belong to the header of the current game.
+func (pr *PGNReader) skipGame() {
+	for {
+		tok, err := pr.next()
+		if err != nil || tok.typ == pgn_token_result {
+			return
+		}
+		if tok.typ == pgn_token_tag_open && tok.afterBlankLine {
+			name, err := pr.next()
+			if err != nil || name.typ == pgn_token_result {
+				return
+			}
+			if name.typ == pgn_token_symbol && name.value == "Event" {
+				pr.unread(name)
+				pr.unread(tok)
+				return
+			}
+		}
+	}
+}
+
+// joinComments concatenates two comments with a space.
+func joinComments(a, b string) string {
+	if a == "" {
+		return b
+	}
+	return a + " " + b
+}
+
+// splitPGNSymbol removes a move number (e.g. '12.' or '12...') from the front
+// of a symbol and splits the rest into the move and its suffix annotation.
+func splitPGNSymbol(sym string) (string, string) {
+	i := 0
+	for i < len(sym) && sym[i] >= '0' && sym[i] <= '9' {
+		i++
+	}
+	if i < len(sym) && sym[i] == '.' {
+		sym = strings.TrimLeft(sym[i:], ".")
+	}
+
+	san := strings.TrimRight(sym, "!?")
+	return san, sym[len(san):]
+}
+
+// peek returns the next token without consuming it.
+func (pr *PGNReader) peek() (pgnToken, error) {
+	if len(pr.peeked) > 0 {
+		return pr.peeked[len(pr.peeked)-1], nil
+	}
+	tok, err := pr.scan()
+	if err != nil {
+		return tok, err
+	}
+	pr.unread(tok)
+	return tok, nil
+}
+
+// next returns and consumes the next token.
+func (pr *PGNReader) next() (pgnToken, error) {
+	if n := len(pr.peeked); n > 0 {
+		tok := pr.peeked[n-1]
+		pr.peeked = pr.peeked[:n-1]
+		return tok, nil
+	}
+	return pr.scan()
+}
+
+// unread makes tok the next token.
+func (pr *PGNReader) unread(tok pgnToken) {
+	pr.peeked = append(pr.peeked, tok)
+}
+
+// readRune reads one character and keeps track of the line number.
+func (pr *PGNReader) readRune() (rune, error) {
+	c, _, err := pr.r.ReadRune()
+	if err != nil {
+		return c, err
+	}
+	pr.lineStart = c == '\n'
+	if c == '\n' {
+		pr.line++
+		pr.newlines++
+	}
+	return c, nil
+}
+
+// skipLine discards the rest of the current line and returns it.
+func (pr *PGNReader) skipLine() (string, error) {
+	var sb strings.Builder
+	for {
+		c, err := pr.readRune()
+		if err != nil || c == '\n' {
+			return sb.String(), err
+		}
+		sb.WriteRune(c)
+	}
+}
+
+// scan reads the next token from the input and notes if it starts after an empty line.
+func (pr *PGNReader) scan() (pgnToken, error) {
+	tok, err := pr.scanToken()
+	tok.afterBlankLine = pr.blankLine
+	return tok, err
+}
+
+// scanToken reads the next token from the input.
+func (pr *PGNReader) scanToken() (pgnToken, error) {
+	for {
+		atLineStart := pr.lineStart
+		blankLine := pr.newlines >= 2
+		c, err := pr.readRune()
+		if err != nil {
+			return pgnToken{}, err
+		}
+		if c != ' ' && c != '\t' && c != '\r' && c != '\n' && !(c == '%' && atLineStart) {
+			// A token starts here.
+			pr.blankLine, pr.newlines = blankLine, 0
+		}
+
+		switch {
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			continue
+		case c == '%' && atLineStart:
+			// Escape mechanism: the whole line is ignored.
+			if _, err := pr.skipLine(); err != nil {
+				return pgnToken{}, err
+			}
+		case c == ';':
+			text, err := pr.skipLine()
+			if err != nil && err != io.EOF {
+				return pgnToken{}, err
+			}
+			return pgnToken{typ: pgn_token_comment, value: strings.TrimSpace(text)}, nil
+		case c == '{':
+			var sb strings.Builder
+			for {
+				c, err = pr.readRune()
+				if err != nil {
+					return pgnToken{}, ErrPGNSyntax
+				}
+				if c == '}' {
+					break
+				}
+				sb.WriteRune(c)
+			}
+			return pgnToken{typ: pgn_token_comment, value: strings.Join(strings.Fields(sb.String()), " ")}, nil
+		case c == '"':
+			var sb strings.Builder
+			for {
+				c, err = pr.readRune()
+				if err != nil || c == '\n' {
+					return pgnToken{}, ErrPGNSyntax
+				}
+				if c == '\\' {
+					if c, err = pr.readRune(); err != nil {
+						return pgnToken{}, ErrPGNSyntax
+					}
+				} else if c == '"' {
+					break
+				}
+				sb.WriteRune(c)
+			}
+			return pgnToken{typ: pgn_token_string, value: sb.String()}, nil
+		case c == '[':
+			return pgnToken{typ: pgn_token_tag_open, value: "["}, nil
+		case c == ']':
+			return pgnToken{typ: pgn_token_tag_close, value: "]"}, nil
+		case c == '(':
+			return pgnToken{typ: pgn_token_variation_open, value: "("}, nil
+		case c == ')':
+			return pgnToken{typ: pgn_token_variation_close, value: ")"}, nil
+		case c == '*':
+			return pgnToken{typ: pgn_token_result, value: "*"}, nil
+		case c == '$':
+			digits, err := pr.readSymbol()
+			if err != nil {
+				return pgnToken{}, err
+			}
+			return pgnToken{typ: pgn_token_nag, value: digits}, nil
+		case isPGNSymbolChar(c):
+			pr.r.UnreadRune()
+			sym, err := pr.readSymbol()
+			if err != nil {
+				return pgnToken{}, err
+			}
+			switch sym {
+			case "1-0", "0-1", "1/2-1/2":
+				return pgnToken{typ: pgn_token_result, value: sym}, nil
+			}
+			return pgnToken{typ: pgn_token_symbol, value: sym}, nil
+		default:
+			return pgnToken{}, ErrPGNSyntax
+		}
+	}
+}
+
+// readSymbol reads a sequence of symbol characters.
+func (pr *PGNReader) readSymbol() (string, error) {
+	var sb strings.Builder
+	for {
+		c, _, err := pr.r.ReadRune()
+		if err == io.EOF {
+			break
+		} else if err != nil {
+			return "", err
+		}
+		if !isPGNSymbolChar(c) {
+			pr.r.UnreadRune()
+			break
+		}
+		pr.lineStart = false
+		sb.WriteRune(c)
+	}
+	return sb.String(), nil
+}
+
+// isPGNSymbolChar returns true for all characters that can be part of
+// a move, a move number, a tag name or a suffix annotation.
+func isPGNSymbolChar(c rune) bool {
+	return (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9') ||
+		strings.ContainsRune("_+#=:-/.!?", c)
+}
@@ -0,0 +1,145 @@
+package chesskimo
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+const testPGN = `[Event "Test"]
+[Site "?"]
+[White "Some \"quoted\" player"]
+[Black "Other"]
+[Result "1-0"]
+
+1. e4 {King's pawn} e5 2. Nf3 Nc6 (2... d6 3. d4 (3. Bc4) 3... exd4 $6) 3. Bb5!? a6
+; rest of line comment
+4. Ba4 Nf6 5. 0-0 Be7 1-0
+
+% escaped line ignored
+[Event "From position"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/4K3 w - - 0 1"]
+
+1.e3 Kd7 2.e4 *
+
+[Event "Broken"]
+
+1. e4 e5 2. Ke3 Nc6 0-1
+
+[Event "After broken"]
+
+{Comment before} 1. d4 d5 1/2-1/2
+`
+
+// TestPGNReader tests reading games with variations, comments, NAGs and FEN tags.
+func TestPGNReader(t *testing.T) {
+	pr := NewPGNReader(strings.NewReader(testPGN))
+
+	// First game: main line with a nested variation.
+	game, err := pr.Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	if game.Tag("White") != `Some "quoted" player` || game.Result != "1-0" {
+		t.Fatalf("Unexpected tags %v and result %s\n", game.Tags, game.Result)
+	}
+	sans := []string{}
+	final := game.Replay(func(b *Board, m *PGNMove) bool {
+		sans = append(sans, m.SAN)
+		return true
+	})
+	if strings.Join(sans, " ") != "e4 e5 Nf3 Nc6 Bb5 a6 Ba4 Nf6 O-O Be7" {
+		t.Fatalf("Unexpected main line: %v\n", sans)
+	}
+	if fen := final.FEN(); fen != "r1bqk2r/1pppbppp/p1n2n2/4p3/B3P3/5N2/PPPP1PPP/RNBQ1RK1 w kq - 4 6" {
+		t.Fatalf("Unexpected final position %s\n", fen)
+	}
+	if game.Moves[0].Comment != "King's pawn" || game.Moves[5].Comment != "rest of line comment" {
+		t.Fatalf("Unexpected comments '%s' and '%s'\n", game.Moves[0].Comment, game.Moves[5].Comment)
+	}
+	if len(game.Moves[4].NAGs) != 1 || game.Moves[4].NAGs[0] != 5 {
+		t.Fatalf("Expected NAG 5 for Bb5!? but got %v\n", game.Moves[4].NAGs)
+	}
+	vars := game.Moves[3].Variations
+	if len(vars) != 1 || len(vars[0]) != 3 || vars[0][0].SAN != "d6" || vars[0][2].SAN != "exd4" {
+		t.Fatalf("Unexpected variation %v\n", vars)
+	}
+	if len(vars[0][2].NAGs) != 1 || vars[0][2].NAGs[0] != 6 {
+		t.Fatalf("Expected NAG 6 for exd4 but got %v\n", vars[0][2].NAGs)
+	}
+	if sub := vars[0][1].Variations; len(sub) != 1 || sub[0][0].SAN != "Bc4" {
+		t.Fatalf("Unexpected nested variation %v\n", sub)
+	}
+
+	// Second game: starts from a FEN.
+	game, err = pr.Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	if final := game.Replay(nil); final.FEN() != "8/3k4/8/8/4P3/8/8/4K3 b - - 0 2" || game.Result != "*" {
+		t.Fatalf("Unexpected final position %s or result %s\n", final.FEN(), game.Result)
+	}
+
+	// Third game: contains an illegal move and is skipped.
+	_, err = pr.Next()
+	if !errors.Is(err, ErrMoveIllegal) {
+		t.Fatalf("Expected an illegal move error but got %v\n", err)
+	}
+
+	// Fourth game: reading continues after the broken game.
+	game, err = pr.Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	if game.Tag("Event") != "After broken" || len(game.Moves) != 2 || game.Moves[0].CommentBefore != "Comment before" {
+		t.Fatalf("Unexpected game %v\n", game)
+	}
+
+	if _, err = pr.Next(); err != io.EOF {
+		t.Fatalf("Expected EOF but got %v\n", err)
+	}
+}
+
+// TestPGNReaderMalformedTag tests if a game with a malformed tag is skipped as a whole.
+func TestPGNReaderMalformedTag(t *testing.T) {
+	input := `[Event "Bad"]
+[Site ?]
+[White "x"]
+[Black "y"]
+
+1. e4 e5 1-0
+
+[Event "Good"]
+
+1. d4 *
+
+[Event "Bad without result"]
+[Site ?]
+[White "x"]
+
+1. e4
+
+[Event "Also good"]
+[Site "?"]
+
+1. c4 *
+`
+	pr := NewPGNReader(strings.NewReader(input))
+	for _, event := range []string{"Good", "Also good"} {
+		if _, err := pr.Next(); !errors.Is(err, ErrPGNSyntax) {
+			t.Fatalf("Expected a syntax error but got %v\n", err)
+		}
+		game, err := pr.Next()
+		if err != nil {
+			t.Fatalf("Unexpected error: %s\n", err)
+		}
+		if game.Tag("Event") != event || len(game.Moves) != 1 {
+			t.Fatalf("Expected the game %s but got %v\n", event, game.Tags)
+		}
+	}
+	if _, err := pr.Next(); err != io.EOF {
+		t.Fatalf("Expected EOF but got %v\n", err)
+	}
+}
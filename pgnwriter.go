@@ -0,0 +1,213 @@
+package chesskimo
+
+import (
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// PGN_LINE_LENGTH is the maximum length of a movetext line written by PGNWriter.
+	PGN_LINE_LENGTH = 79
+)
+
+// sevenTagRoster contains the tags every PGN game must have, in the required order.
+var sevenTagRoster = [7]string{"Event", "Site", "Date", "Round", "White", "Black", "Result"}
+
+// NewPGNGame creates a game without moves that starts in position 'start'.
+// All tags of the Seven Tag Roster are set to unknown values. If start is not
+// the standard starting position, the SetUp and FEN tags are added.
+func NewPGNGame(start Board) *PGNGame {
+	g := &PGNGame{Start: start, Result: "*", end: start, endValid: true}
+	for _, name := range sevenTagRoster {
+		g.SetTag(name, "?")
+	}
+	g.SetTag("Date", "????.??.??")
+	g.SetTag("Result", "*")
+
+	standard := NewBoard()
+	if fen := start.FEN(); fen != standard.FEN() {
+		g.SetTag("SetUp", "1")
+		g.SetTag("FEN", fen)
+	}
+	return g
+}
+
+// SetTag sets the value of a tag. A new tag is added after all existing tags.
+func (g *PGNGame) SetTag(name, value string) {
+	for i := range g.Tags {
+		if g.Tags[i].Name == name {
+			g.Tags[i].Value = value
+			return
+		}
+	}
+	g.Tags = append(g.Tags, PGNTag{Name: name, Value: value})
+}
+
+// AddMove appends the legal move m to the main line with an optional comment.
+func (g *PGNGame) AddMove(m BitMove, comment string) {
+	if !g.endValid || g.endMoves != len(g.Moves) {
+		// The moves were not added by AddMove, e.g. the game was read by PGNReader.
+		g.end, g.endValid = g.Replay(nil), true
+	}
+	g.Moves = append(g.Moves, PGNMove{Move: m, SAN: g.end.MoveToSAN(m), Comment: comment})
+	g.end.MakeLegalMove(m)
+	g.endMoves = len(g.Moves)
+}
+
+// PGNEvalComment formats a search result as move comment like '+0.35/12 1.204s'.
+// The score is given in pawns from the view of the player who made the move,
+// mates are written as '+M3' or '-M2'.
+func PGNEvalComment(score, depth int, elapsed time.Duration) string {
+	eval := ""
+	if score >= MATE_BOUND {
+		eval = "+M" + strconv.Itoa((MATE_SCORE-score+1)/2)
+	} else if score <= -MATE_BOUND {
+		eval = "-M" + strconv.Itoa((MATE_SCORE+score)/2)
+	} else {
+		eval = fmt.Sprintf("%+.2f", float64(score)/100)
+	}
+	return fmt.Sprintf("%s/%d %.3fs", eval, depth, elapsed.Seconds())
+}
+
+// PGNResult returns the PGN result string of a game state.
+func PGNResult(state State) string {
+	switch state {
+	case GAMESTATE_WHITE_WIN:
+		return "1-0"
+	case GAMESTATE_BLACK_WIN:
+		return "0-1"
+	case GAMESTATE_DRAW:
+		return "1/2-1/2"
+	}
+	return "*"
+}
+
+// PGNWriter writes games in PGN export format.
+type PGNWriter struct {
+	w io.Writer
+}
+
+// NewPGNWriter creates a writer that writes games to w.
+func NewPGNWriter(w io.Writer) *PGNWriter {
+	return &PGNWriter{w: w}
+}
+
+// Write writes a game followed by an empty line. The tags of the Seven Tag Roster
+// come first (unknown ones are written as '?'), the Result tag is taken from g.Result.
+func (pw *PGNWriter) Write(g *PGNGame) error {
+	result := g.Result
+	if result == "" {
+		result = "*"
+	}
+
+	var sb strings.Builder
+	for _, name := range sevenTagRoster {
+		value := g.Tag(name)
+		if name == "Result" {
+			value = result
+		} else if value == "" {
+			value = "?"
+		}
+		writePGNTag(&sb, name, value)
+	}
+	for _, t := range g.Tags {
+		if !isSevenTagRoster(t.Name) {
+			writePGNTag(&sb, t.Name, t.Value)
+		}
+	}
+	sb.WriteString("\n")
+
+	tokens := pgnMovetext(g.Moves, int(g.Start.MoveNumber))
+	tokens = append(tokens, result)
+	length := 0
+	for _, tok := range tokens {
+		if length > 0 && length+1+len(tok) > PGN_LINE_LENGTH {
+			sb.WriteString("\n")
+			length = 0
+		} else if length > 0 {
+			sb.WriteString(" ")
+			length++
+		}
+		sb.WriteString(tok)
+		length += len(tok)
+	}
+	sb.WriteString("\n\n")
+
+	_, err := io.WriteString(pw.w, sb.String())
+	return err
+}
+
+// writePGNTag writes a tag pair and escapes its value.
+func writePGNTag(sb *strings.Builder, name, value string) {
+	value = strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(value)
+	sb.WriteString("[" + name + " \"" + value + "\"]\n")
+}
+
+func isSevenTagRoster(name string) bool {
+	for _, n := range sevenTagRoster {
+		if n == name {
+			return true
+		}
+	}
+	return false
+}
+
+// pgnMovetext converts a line of moves starting at the given ply into tokens.
+// Variations are written after the move they replace. Comments are split into
+// words so that long comments can be wrapped.
+func pgnMovetext(moves []PGNMove, ply int) []string {
+	tokens := []string{}
+	// A move of black needs its number after anything that interrupts the move sequence.
+	needNumber := true
+
+	for _, m := range moves {
+		if m.CommentBefore != "" {
+			tokens = appendPGNComment(tokens, m.CommentBefore)
+			needNumber = true
+		}
+
+		if ply%2 == 0 {
+			tokens = append(tokens, strconv.Itoa(ply/2+1)+".")
+		} else if needNumber {
+			tokens = append(tokens, strconv.Itoa(ply/2+1)+"...")
+		}
+		tokens = append(tokens, m.SAN)
+		needNumber = false
+
+		for _, nag := range m.NAGs {
+			tokens = append(tokens, "$"+strconv.Itoa(nag))
+		}
+		if m.Comment != "" {
+			tokens = appendPGNComment(tokens, m.Comment)
+			needNumber = true
+		}
+		for _, v := range m.Variations {
+			vtokens := pgnMovetext(v, ply)
+			if len(vtokens) == 0 {
+				continue
+			}
+			vtokens[0] = "(" + vtokens[0]
+			vtokens[len(vtokens)-1] += ")"
+			tokens = append(tokens, vtokens...)
+			needNumber = true
+		}
+		ply++
+	}
+
+	return tokens
+}
+
+// appendPGNComment adds a comment in braces. Braces inside the comment are removed.
+func appendPGNComment(tokens []string, comment string) []string {
+	comment = strings.NewReplacer("{", "", "}", "").Replace(comment)
+	words := strings.Fields(comment)
+	if len(words) == 0 {
+		return tokens
+	}
+	words[0] = "{" + words[0]
+	words[len(words)-1] += "}"
+	return append(tokens, words...)
+}
@@ -0,0 +1,109 @@
+package chesskimo
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestPGNWriter tests the export of a game with tags, comments and line wrapping.
+func TestPGNWriter(t *testing.T) {
+	board := NewBoard()
+	if err := board.SetFEN("4k3/8/8/8/8/8/4P3/R3K3 b Q - 0 30"); err != nil {
+		t.Fatalf("Invalid FEN: %s\n", err)
+	}
+	game := NewPGNGame(board)
+	game.SetTag("White", "Chesskimo")
+	game.SetTag("Annotator", `The "engine"`)
+	for i, san := range []string{"Kd7", "O-O-O+", "Kc7", "e4"} {
+		current := game.Replay(nil)
+		m, err := current.ParseSAN(san)
+		if err != nil {
+			t.Fatalf("Move %s: %s\n", san, err)
+		}
+		comment := ""
+		if i == 1 {
+			comment = PGNEvalComment(350, 12, 1500*time.Millisecond)
+		}
+		game.AddMove(m, comment)
+	}
+
+	out := &bytes.Buffer{}
+	if err := NewPGNWriter(out).Write(game); err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	expected := `[Event "?"]
+[Site "?"]
+[Date "????.??.??"]
+[Round "?"]
+[White "Chesskimo"]
+[Black "?"]
+[Result "*"]
+[SetUp "1"]
+[FEN "4k3/8/8/8/8/8/4P3/R3K3 b Q - 0 30"]
+[Annotator "The \"engine\""]
+
+30... Kd7 31. O-O-O+ {+3.50/12 1.500s} 31... Kc7 32. e4 *
+
+`
+	if out.String() != expected {
+		t.Fatalf("Expected:\n%s\nbut got:\n%s\n", expected, out.String())
+	}
+}
+
+// TestPGNRoundTrip tests if a game that was written is read back unchanged.
+func TestPGNRoundTrip(t *testing.T) {
+	game, err := NewPGNReader(strings.NewReader(testPGN)).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	// A long comment must be wrapped.
+	game.Moves[2].Comment = strings.Repeat("very long comment ", 10)
+
+	out := &bytes.Buffer{}
+	if err := NewPGNWriter(out).Write(game); err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+	written := out.String()
+	for _, line := range strings.Split(written, "\n") {
+		if len(line) > PGN_LINE_LENGTH {
+			t.Fatalf("Line is too long: %s\n", line)
+		}
+	}
+
+	again, err := NewPGNReader(out).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n%s\n", err, written)
+	}
+	out2 := &bytes.Buffer{}
+	NewPGNWriter(out2).Write(again)
+	if written != out2.String() {
+		t.Fatalf("Expected:\n%s\nbut got:\n%s\n", written, out2.String())
+	}
+}
+
+// TestPGNAddMove tests if moves are added to games of the writer and of the reader.
+func TestPGNAddMove(t *testing.T) {
+	game := NewPGNGame(NewBoard())
+	read, err := NewPGNReader(strings.NewReader(testPGN)).Next()
+	if err != nil {
+		t.Fatalf("Unexpected error: %s\n", err)
+	}
+
+	for _, g := range []*PGNGame{game, read} {
+		for i := 0; i < 20; i++ {
+			board := g.Replay(nil)
+			mlist := MoveList{}
+			board.GenerateAllLegalMoves(&mlist)
+			if mlist.Size == 0 {
+				break
+			}
+			m := mlist.Moves[i%int(mlist.Size)]
+			g.AddMove(m, "")
+			if last := g.Moves[len(g.Moves)-1]; last.SAN != board.MoveToSAN(m) {
+				t.Fatalf("Expected SAN %s but got %s\n", board.MoveToSAN(m), last.SAN)
+			}
+		}
+	}
+}
@@ -0,0 +1,92 @@
+package chesskimo
+
+const (
+	// DELTA_MARGIN is the safety margin of delta pruning. A capture is skipped if even
+	// winning the captured piece plus this margin cannot raise the score to alpha.
+	DELTA_MARGIN = 200
+)
+
+// quiescence searches captures and promotions until the position is quiet, so that
+// the evaluation is not taken in the middle of an exchange (horizon effect). When in
+// check all evasions are searched. Nodes are counted like nodes of the main search.
+func (s *abSearch) quiescence(ply, alpha, beta int) int {
+	// The principal variation ends before the quiescence search.
+	s.pvLen[ply] = ply
+	s.nodes++
+	if s.nodes%stop_check_interval == 0 {
+		s.checkStop()
+	}
+	if s.stopped {
+		return 0
+	}
+	if ply > s.seldepth {
+		s.seldepth = ply
+	}
+	if s.history.IsSearchDraw(&s.board) {
+		// Only check evasions can repeat a position here.
+		return 0
+	}
+	if ply >= MAX_PLY {
+		return Evaluate(&s.board)
+	}
+
+	inCheck := s.board.InCheck()
+	mlist := MoveList{}
+	standPat := -INFINITY
+	if inCheck {
+		// There is no stand pat in check, every evasion must be tried.
+		s.board.GenerateAllLegalMoves(&mlist)
+		if mlist.Size == 0 {
+			// Checkmate.
+			return -MATE_SCORE + ply
+		}
+	} else {
+		// The player to move does not have to capture, so the static
+		// evaluation is a lower bound of the score.
+		standPat = Evaluate(&s.board)
+		if standPat >= beta {
+			return standPat
+		}
+		if standPat > alpha {
+			alpha = standPat
+		}
+		s.board.GenerateCaptures(&mlist)
+	}
+
+	// Quiet evasions have no gain and are searched after the captures.
+	for i := uint32(0); i < mlist.Size; i++ {
+		mlist.Scores[i] = int32(mvvLva(&s.board, mlist.Moves[i]))
+	}
+
+	best := standPat
+	for i := uint32(0); i < mlist.Size; i++ {
+		mlist.PickNext(i)
+		move := mlist.Moves[i]
+		if !inCheck && standPat+captureGain(&s.board, move)+DELTA_MARGIN <= alpha {
+			// Delta pruning: this capture cannot raise alpha.
+			continue
+		}
+
+		s.history.Push(s.board.Hash)
+		undo := s.board.MakeLegalMove(move)
+		score := -s.quiescence(ply+1, -beta, -alpha)
+		s.board.UnmakeMove(undo)
+		s.history.Pop()
+
+		if s.stopped {
+			return 0
+		}
+		if score > best {
+			best = score
+			if score > alpha {
+				alpha = score
+				if alpha >= beta {
+					// Beta cutoff.
+					break
+				}
+			}
+		}
+	}
+
+	return best
+}
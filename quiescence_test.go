@@ -0,0 +1,53 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestQuiescence tests that the quiescence search resolves captures.
+func TestQuiescence(t *testing.T) {
+	type set struct {
+		Fen string
+		// Bounds of the expected score relative to the player to move.
+		Min, Max int
+	}
+	testsets := []set{
+		// The hanging queen is captured.
+		{"4k3/8/8/3q4/8/8/8/3QK3 w - - 0 1", 800, 2000},
+		// Taking the defended pawn loses the queen, so white stands pat.
+		{"4k3/8/3p4/4p3/8/8/8/4QK2 w - - 0 1", 500, 900},
+		// In check there is no stand pat, the evasions are searched.
+		{"7k/8/8/8/8/8/8/r5K1 w - - 0 1", -800, -300},
+		// Black is smothered.
+		{"6rk/5Npp/8/8/8/8/8/6K1 b - - 0 1", -MATE_SCORE, -MATE_SCORE},
+	}
+
+	for _, set := range testsets {
+		engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+		if err := engine.board.SetFEN(set.Fen); err != nil {
+			t.Fatalf(err.Error())
+		}
+		dostop := uint32(0)
+		s := abSearch{engine: engine, board: engine.board, tt: engine.tt, dostop: &dostop, tm: engine.newTimeManager(&SearchSettings{}), ss: &SearchSettings{}}
+		score := s.quiescence(0, -INFINITY, INFINITY)
+		if score < set.Min || score > set.Max {
+			t.Fatalf("Expected a score in [%d, %d] for FEN %s but got %d\n", set.Min, set.Max, set.Fen, score)
+		}
+		if s.nodes == 0 {
+			t.Fatalf("Expected the nodes of FEN %s to be counted\n", set.Fen)
+		}
+	}
+}
+
+// TestAlphaBetaHorizon tests that a shallow search does not win material that is lost right after its horizon.
+func TestAlphaBetaHorizon(t *testing.T) {
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	if err := engine.board.SetFEN("4k3/8/3p4/4p3/8/8/8/4QK2 w - - 0 1"); err != nil {
+		t.Fatalf(err.Error())
+	}
+	dostop := uint32(0)
+	sr := AlphaBetaSearch(engine, &SearchSettings{MaxDepth: 1}, &dostop)
+	if sr.Move.MiniNotation() == "e1e5" {
+		t.Fatalf("Expected the search to avoid losing the queen with e1e5\n")
+	}
+}
@@ -0,0 +1,216 @@
+package chesskimo
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	// ErrSANAmbiguous is returned if a SAN move matches more than one legal move.
+	ErrSANAmbiguous = errors.New("SAN move is ambiguous")
+
+	// sanPieces contains the SAN letter of every piece type. Pawns have none.
+	sanPieces = map[Piece]string{
+		KNIGHT: "N",
+		BISHOP: "B",
+		ROOK:   "R",
+		QUEEN:  "Q",
+		KING:   "K",
+	}
+)
+
+// MoveToSAN returns the legal move m in Standard Algebraic Notation, e.g. 'Nbd7', 'exd6',
+// 'e8=Q+' or 'O-O#'.
+func (b *Board) MoveToSAN(m BitMove) string {
+	from, to, promo := m.All()
+	ptype := b.Squares[from] & PIECE_MASK
+
+	var sb strings.Builder
+	switch {
+	case ptype == KING && to == from+2:
+		sb.WriteString("O-O")
+	case ptype == KING && from == to+2:
+		sb.WriteString("O-O-O")
+	case ptype == PAWN:
+		if from.File() != to.File() {
+			// Pawn captures always name the file the pawn came from.
+			sb.WriteString(PrintBoardIndex[from][0:1] + "x")
+		}
+		sb.WriteString(PrintBoardIndex[to])
+		if promo != NONE {
+			sb.WriteString("=" + sanPieces[promo])
+		}
+	default:
+		sb.WriteString(sanPieces[ptype])
+		sb.WriteString(b.sanDisambiguation(from, to, ptype))
+		if !b.Squares[to].IsEmpty() {
+			sb.WriteString("x")
+		}
+		sb.WriteString(PrintBoardIndex[to])
+	}
+
+	// Find out if the move gives check or mate.
+	after := *b
+	after.MakeLegalMove(m)
+	mlist := MoveList{}
+	after.GenerateAllLegalMoves(&mlist)
+	if after.CheckInfo != CHECK_NONE {
+		if mlist.Size == 0 {
+			sb.WriteString("#")
+		} else {
+			sb.WriteString("+")
+		}
+	}
+
+	return sb.String()
+}
+
+// sanDisambiguation returns the file, rank or square of 'from' if another piece
+// of the same type can also move to 'to'. The file is preferred over the rank.
+func (b *Board) sanDisambiguation(from, to Square, ptype Piece) string {
+	mlist := MoveList{}
+	b.GenerateAllLegalMoves(&mlist)
+
+	ambiguous, sameFile, sameRank := false, false, false
+	for i := uint32(0); i < mlist.Size; i++ {
+		other := mlist.Moves[i].From()
+		if mlist.Moves[i].To() != to || other == from || b.Squares[other]&PIECE_MASK != ptype {
+			continue
+		}
+		ambiguous = true
+		if other.File() == from.File() {
+			sameFile = true
+		}
+		if other.Rank() == from.Rank() {
+			sameRank = true
+		}
+	}
+
+	square := PrintBoardIndex[from]
+	switch {
+	case !ambiguous:
+		return ""
+	case !sameFile:
+		return square[0:1]
+	case !sameRank:
+		return square[1:2]
+	}
+	return square
+}
+
+// ParseSAN parses a move in Standard Algebraic Notation and returns it, if it is legal
+// in the current position. Common variants are accepted: castling with zeros ('0-0'),
+// promotions without '=' ('e8Q'), long notation ('Ng1-f3'), and missing or superfluous
+// check marks and annotations ('Nf3+', 'e4!?').
+func (b *Board) ParseSAN(san string) (BitMove, error) {
+	san = strings.TrimRight(strings.TrimSpace(san), "+#!?")
+
+	switch san {
+	case "O-O", "0-0":
+		return b.parseSANCastling(true)
+	case "O-O-O", "0-0-0":
+		return b.parseSANCastling(false)
+	}
+
+	// Remove capture and move markers, they are not needed to find the move.
+	san = strings.NewReplacer("x", "", "-", "", ":", "").Replace(san)
+	if len(san) < 2 {
+		return BitMove(0), ErrInvalidMoveNotation
+	}
+
+	ptype := PAWN
+	for p, letter := range sanPieces {
+		if san[0:1] == letter {
+			ptype = p
+			san = san[1:]
+			break
+		}
+	}
+
+	// Promotions follow the target square, e.g. 'e8=Q' or 'e8Q'.
+	promo := NONE
+	if n := len(san); n > 2 && ptype == PAWN {
+		letter := strings.ToUpper(san[n-1:])
+		for p, l := range sanPieces {
+			if l == letter && p != KING {
+				promo = p
+				san = strings.TrimSuffix(san[:n-1], "=")
+				break
+			}
+		}
+	}
+
+	if len(san) < 2 || len(san) > 4 {
+		return BitMove(0), ErrInvalidMoveNotation
+	}
+	to, err := parseMoveSquare(san[len(san)-2:])
+	if err != nil {
+		return BitMove(0), err
+	}
+
+	// What is left is the file and/or rank of the piece that moves.
+	file, rank := OTB, OTB
+	for _, c := range san[:len(san)-2] {
+		switch {
+		case c >= 'a' && c <= 'h':
+			file = Square(c - 'a')
+		case c >= '1' && c <= '8':
+			rank = Square(c - '1')
+		default:
+			return BitMove(0), ErrInvalidMoveNotation
+		}
+	}
+
+	mlist := MoveList{}
+	b.GenerateAllLegalMoves(&mlist)
+	found := BitMove(0)
+	count := 0
+	missingPromo := false
+	for i := uint32(0); i < mlist.Size; i++ {
+		m := mlist.Moves[i]
+		from := m.From()
+		if m.To() != to || b.Squares[from]&PIECE_MASK != ptype {
+			continue
+		}
+		if (file != OTB && from.File() != file) || (rank != OTB && from.Rank() != rank) {
+			continue
+		}
+		if m.PromotedPiece() != promo {
+			if promo == NONE {
+				missingPromo = true
+			}
+			continue
+		}
+		found = m
+		count++
+	}
+
+	switch {
+	case count == 1:
+		return found, nil
+	case count > 1:
+		return BitMove(0), ErrSANAmbiguous
+	case missingPromo:
+		return BitMove(0), ErrMoveMissingPromotion
+	}
+	return BitMove(0), ErrMoveIllegal
+}
+
+// parseSANCastling returns the castling move to the given side, if it is legal.
+func (b *Board) parseSANCastling(short bool) (BitMove, error) {
+	from := b.Kings[b.Player]
+	to := CASTLING_PATH_LONG[b.Player][1]
+	if short {
+		to = CASTLING_PATH_SHORT[b.Player][1]
+	}
+
+	mlist := MoveList{}
+	b.GenerateAllLegalMoves(&mlist)
+	m := NewBitMove(from, to, NONE)
+	for i := uint32(0); i < mlist.Size; i++ {
+		if mlist.Moves[i] == m {
+			return m, nil
+		}
+	}
+	return BitMove(0), ErrMoveIllegal
+}
@@ -0,0 +1,132 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+var sanTestFENs = []string{
+	"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+	"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+	"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 1",
+	"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+	"rnbq1k1r/pp1Pbppp/2p5/8/2B5/8/PPP1NnPP/RNBQK2R w KQ - 1 8",
+	"r4rk1/1pp1qppp/p1np1n2/2b1p1B1/2B1P1b1/P1NP1N2/1PP1QPPP/R4RK1 w - - 0 10",
+	"3k4/8/8/8/8/8/8/R3K2R b KQ - 0 1",
+}
+
+// TestMoveToSAN tests the SAN output of single moves.
+func TestMoveToSAN(t *testing.T) {
+	type set struct {
+		FEN  string
+		Move string
+		SAN  string
+	}
+	testsets := []set{
+		{sanTestFENs[0], "e2e4", "e4"},
+		{sanTestFENs[0], "g1f3", "Nf3"},
+		{sanTestFENs[1], "e1g1", "O-O"},
+		{sanTestFENs[1], "e1c1", "O-O-O"},
+		{sanTestFENs[1], "e5f7", "Nxf7"},
+		{sanTestFENs[1], "d5e6", "dxe6"},
+		{sanTestFENs[1], "g2h3", "gxh3"},
+		{sanTestFENs[1], "c3b5", "Nb5"},
+		// Both rooks can reach e1.
+		{sanTestFENs[5], "a1e1", "Rae1"},
+		{sanTestFENs[5], "f1e1", "Rfe1"},
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7a8q", "bxa8=Q+"},
+		{"r3k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8n", "b8=N"},
+		{sanTestFENs[4], "d7c8q", "dxc8=Q"},
+		{sanTestFENs[4], "d7c8n", "dxc8=N"},
+		{sanTestFENs[4], "e1f2", "Kxf2"},
+		{sanTestFENs[2], "b4f4", "Rxf4+"},
+		{"7k/8/6K1/8/8/8/8/R7 w - - 0 1", "a1a8", "Ra8#"},
+		// Knights on the same file need the rank, on the same file and rank the square.
+		{"4k3/8/8/1N6/8/1N6/8/4K3 w - - 0 1", "b5d4", "N5d4"},
+		{"4k3/8/8/1N3N2/8/1N6/8/4K3 w - - 0 1", "b5d4", "Nb5d4"},
+		{"4k3/8/8/1N3N2/8/1N6/8/4K3 w - - 0 1", "f5d4", "Nfd4"},
+		{"4k3/8/4p3/3P4/8/8/8/4K3 w - e7 0 1", "d5d6", "d6"},
+		{"4k3/8/8/3Pp3/8/8/8/4K3 w - e6 0 1", "d5e6", "dxe6"},
+	}
+
+	for i, ts := range testsets {
+		board := NewBoard()
+		if err := board.SetFEN(ts.FEN); err != nil {
+			t.Fatalf("Test %d: invalid FEN: %s\n", i, err)
+		}
+		m, err := board.ParseMove(ts.Move)
+		if err != nil {
+			t.Fatalf("Test %d: move %s: %s\n", i, ts.Move, err)
+		}
+		if san := board.MoveToSAN(m); san != ts.SAN {
+			t.Fatalf("Test %d: expected %s but got %s\n", i, ts.SAN, san)
+		}
+	}
+}
+
+// TestParseSAN tests the parsing of SAN moves and their common variants.
+func TestParseSAN(t *testing.T) {
+	type set struct {
+		FEN  string
+		SAN  string
+		Err  error
+		Move string
+	}
+	testsets := []set{
+		{sanTestFENs[0], "e4", nil, "e2e4"},
+		{sanTestFENs[0], "Nf3!?", nil, "g1f3"},
+		{sanTestFENs[0], "Ng1-f3", nil, "g1f3"},
+		{sanTestFENs[0], "e5", ErrMoveIllegal, ""},
+		{sanTestFENs[0], "Nd2", ErrMoveIllegal, ""},
+		{sanTestFENs[0], "Zf3", ErrInvalidMoveNotation, ""},
+		{sanTestFENs[1], "0-0", nil, "e1g1"},
+		{sanTestFENs[1], "O-O-O+", nil, "e1c1"},
+		{sanTestFENs[5], "Re1", ErrSANAmbiguous, ""},
+		{sanTestFENs[5], "Rae1", nil, "a1e1"},
+		{sanTestFENs[5], "Rfd1", nil, "f1d1"},
+		{sanTestFENs[1], "Nxf7", nil, "e5f7"},
+		{sanTestFENs[1], "Nf7", nil, "e5f7"},
+		{sanTestFENs[1], "dxe6", nil, "d5e6"},
+		{sanTestFENs[3], "bxa1=Q", ErrMoveIllegal, ""},
+		{sanTestFENs[4], "dxc8=Q", nil, "d7c8q"},
+		{sanTestFENs[4], "dxc8Q", nil, "d7c8q"},
+		{sanTestFENs[4], "dc8N", nil, "d7c8n"},
+		{sanTestFENs[4], "dxc8", ErrMoveMissingPromotion, ""},
+		{sanTestFENs[4], "O-O", nil, "e1g1"},
+		{sanTestFENs[6], "Kc7", nil, "d8c7"},
+		{sanTestFENs[6], "O-O", ErrMoveIllegal, ""},
+	}
+
+	for i, ts := range testsets {
+		board := NewBoard()
+		if err := board.SetFEN(ts.FEN); err != nil {
+			t.Fatalf("Test %d: invalid FEN: %s\n", i, err)
+		}
+		m, err := board.ParseSAN(ts.SAN)
+		if err != ts.Err {
+			t.Fatalf("Test %d: expected error %v but got %v\n", i, ts.Err, err)
+		}
+		if err == nil && m.MiniNotation() != ts.Move {
+			t.Fatalf("Test %d: expected %s but got %s\n", i, ts.Move, m.MiniNotation())
+		}
+	}
+}
+
+// TestSANRoundTrip tests if every legal move is parsed back from its SAN.
+func TestSANRoundTrip(t *testing.T) {
+	for _, fen := range sanTestFENs {
+		board := NewBoard()
+		if err := board.SetFEN(fen); err != nil {
+			t.Fatalf("Invalid FEN %s: %s\n", fen, err)
+		}
+		mlist := MoveList{}
+		board.GenerateAllLegalMoves(&mlist)
+		for i := uint32(0); i < mlist.Size; i++ {
+			m := mlist.Moves[i]
+			san := board.MoveToSAN(m)
+			parsed, err := board.ParseSAN(san)
+			if err != nil || parsed != m {
+				t.Fatalf("Move %s in %s: SAN %s was parsed as %s (%v)\n", m.MiniNotation(), fen, san, parsed.MiniNotation(), err)
+			}
+		}
+	}
+}
@@ -0,0 +1,122 @@
+package chesskimo
+
+// SEE returns the static exchange evaluation of the move m: the material the
+// player to move wins or loses if both sides keep capturing on the target square
+// of m with their least valuable piece, and each side may stop when it is ahead.
+// Pins are ignored. Quiet moves are evaluated as if the moving piece could be captured.
+func (b *Board) SEE(m BitMove) int {
+	from, to, promo := m.All()
+	squares := b.Squares
+
+	gain := [32]int{}
+	gain[0] = captureGain(b, m)
+
+	// The piece standing on the target square, which is the next one to be captured.
+	onSquare := squares[from]
+	if promo != NONE {
+		onSquare = promo | onSquare.PieceColor()
+	}
+	if onSquare&PIECE_MASK == PAWN && to == b.EpSquare {
+		squares[Square(int8(to)+PAWN_PUSH_DIRS[onSquare.PieceColor().Flip()])] = EMPTY
+	}
+	squares[from] = EMPTY
+	squares[to] = onSquare
+
+	color := onSquare.PieceColor().Flip()
+	d := 0
+	for d < len(gain)-1 {
+		attacker := leastValuableAttacker(&squares, to, color)
+		if attacker == OTB {
+			break
+		}
+		if squares[attacker]&PIECE_MASK == KING {
+			// The king may only capture if the square is not defended anymore.
+			squares[attacker] = EMPTY
+			defended := leastValuableAttacker(&squares, to, color.Flip()) != OTB
+			squares[attacker] = KING | color
+			if defended {
+				break
+			}
+		}
+
+		d++
+		gain[d] = MaterialValues[onSquare&PIECE_MASK] - gain[d-1]
+		onSquare = squares[attacker]
+		squares[attacker] = EMPTY
+		squares[to] = onSquare
+		color = color.Flip()
+	}
+
+	// Every side stops capturing when continuing would lose material.
+	for ; d > 0; d-- {
+		if gain[d] > -gain[d-1] {
+			gain[d-1] = -gain[d]
+		}
+	}
+
+	return gain[0]
+}
+
+// leastValuableAttacker returns the square of the cheapest piece of 'color' that attacks
+// sq on the given squares or OTB if there is none.
+func leastValuableAttacker(squares *[64 * 2]Piece, sq Square, color Color) Square {
+	// Pawns are found by looking in the reverse capture direction.
+	pawn := PAWN | color
+	for d := 0; d < 2; d++ {
+		from := Square(int8(sq) + PAWN_CAPTURE_DIRS[color.Flip()][d])
+		if from.OnBoard() && squares[from] == pawn {
+			return from
+		}
+	}
+
+	knight := KNIGHT | color
+	for d := 0; d < 8; d++ {
+		from := Square(int8(sq) + KNIGHT_DIRS[d])
+		if from.OnBoard() && squares[from] == knight {
+			return from
+		}
+	}
+
+	// The nearest piece in every direction may be a slider attacking sq.
+	best, bestValue := OTB, INFINITY
+	for d := 0; d < 4; d++ {
+		if from := firstPieceInDirection(squares, sq, DIAGONAL_DIRS[d]); from != OTB {
+			if p := squares[from]; p == BISHOP|color || p == QUEEN|color {
+				if v := MaterialValues[p&PIECE_MASK]; v < bestValue {
+					best, bestValue = from, v
+				}
+			}
+		}
+		if from := firstPieceInDirection(squares, sq, ORTHOGONAL_DIRS[d]); from != OTB {
+			if p := squares[from]; p == ROOK|color || p == QUEEN|color {
+				if v := MaterialValues[p&PIECE_MASK]; v < bestValue {
+					best, bestValue = from, v
+				}
+			}
+		}
+	}
+	if best != OTB {
+		return best
+	}
+
+	king := KING | color
+	for d := 0; d < 8; d++ {
+		from := Square(int8(sq) + KING_DIRS[d])
+		if from.OnBoard() && squares[from] == king {
+			return from
+		}
+	}
+
+	return OTB
+}
+
+// firstPieceInDirection returns the square of the first piece seen from sq in
+// direction dir or OTB if there is none.
+func firstPieceInDirection(squares *[64 * 2]Piece, sq Square, dir int8) Square {
+	for to := Square(int8(sq) + dir); to.OnBoard(); to = Square(int8(to) + dir) {
+		if !squares[to].IsEmpty() {
+			return to
+		}
+	}
+	return OTB
+}
@@ -0,0 +1,44 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestSEE tests the static exchange evaluation of captures and quiet moves.
+func TestSEE(t *testing.T) {
+	type set struct {
+		Fen  string
+		Move string
+		SEE  int
+	}
+	testsets := []set{
+		// Undefended pawn.
+		{"1k1r4/1pp4p/p7/4p3/8/P5P1/1PP4P/2K1R3 w - - 0 1", "e1e5", 100},
+		// Exchange with x-rays behind the rook and the bishop.
+		{"1k1r3q/1ppn3p/p4b2/4p3/8/P2N2P1/1PP1R1BP/2K1Q3 w - - 0 1", "d3e5", -220},
+		// The queen moves to a square attacked by a pawn.
+		{"4k3/8/3p4/8/8/8/8/4QK2 w - - 0 1", "e1e5", -900},
+		// En passent.
+		{"4k3/8/8/3pP3/8/8/8/4K3 w - d6 0 1", "e5d6", 100},
+		// The king recaptures the rook.
+		{"8/8/2k5/3p4/8/8/8/3RK3 w - - 0 1", "d1d5", -400},
+		// The king cannot recapture because the square is defended.
+		{"8/8/2k5/3p4/8/8/3R4/3RK3 w - - 0 1", "d2d5", 100},
+		// Promotion.
+		{"4k3/1P6/8/8/8/8/8/4K3 w - - 0 1", "b7b8q", 800},
+	}
+
+	for _, set := range testsets {
+		board := NewBoard()
+		if err := board.SetFEN(set.Fen); err != nil {
+			t.Fatalf(err.Error())
+		}
+		move, err := board.ParseMove(set.Move)
+		if err != nil {
+			t.Fatalf("Move %s in FEN %s: %s\n", set.Move, set.Fen, err)
+		}
+		if see := board.SEE(move); see != set.SEE {
+			t.Fatalf("Expected SEE %d for %s in FEN %s but got %d\n", set.SEE, set.Move, set.Fen, see)
+		}
+	}
+}
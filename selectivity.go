@@ -0,0 +1,84 @@
+package chesskimo
+
+import (
+	"math"
+)
+
+const (
+	// NULL_MOVE_MIN_DEPTH is the lowest depth at which a null move is tried.
+	NULL_MOVE_MIN_DEPTH = 3
+	// RFP_MAX_DEPTH is the highest depth of reverse futility pruning and
+	// RFP_MARGIN the margin per ply by which the static evaluation must exceed beta.
+	RFP_MAX_DEPTH = 6
+	RFP_MARGIN    = 80
+	// FUTILITY_MAX_DEPTH is the highest depth of futility pruning and FUTILITY_MARGIN
+	// the margin per ply a quiet move could add to the static evaluation.
+	FUTILITY_MAX_DEPTH = 3
+	FUTILITY_MARGIN    = 120
+	// LMP_MAX_DEPTH is the highest depth of late move pruning.
+	LMP_MAX_DEPTH = 3
+	// LMR_MIN_DEPTH is the lowest depth at which late moves are reduced and
+	// LMR_MIN_MOVES the number of moves that are always searched to full depth.
+	LMR_MIN_DEPTH = 3
+	LMR_MIN_MOVES = 3
+)
+
+// Selectivity switches the pruning and reduction techniques of the alpha-beta search.
+// Every technique is a UCI option so it can be tested on its own.
+type Selectivity struct {
+	NullMove        bool
+	LMR             bool
+	ReverseFutility bool
+	Futility        bool
+	LateMovePruning bool
+}
+
+// FULL_SELECTIVITY enables all techniques. It is the default of the engine.
+var FULL_SELECTIVITY = Selectivity{
+	NullMove:        true,
+	LMR:             true,
+	ReverseFutility: true,
+	Futility:        true,
+	LateMovePruning: true,
+}
+
+// lmrReductions contains the depth reduction of late moves by depth and move number.
+var lmrReductions [64][64]int
+
+func init() {
+	for depth := 1; depth < 64; depth++ {
+		for moves := 1; moves < 64; moves++ {
+			lmrReductions[depth][moves] = int(0.75 + math.Log(float64(depth))*math.Log(float64(moves))/2.25)
+		}
+	}
+}
+
+// lmrReduction returns the reduction of the n-th move (counting from 1) at the given depth.
+// The reduced search has at least depth 1.
+func lmrReduction(depth, n int) int {
+	if depth > 63 {
+		depth = 63
+	}
+	if n > 63 {
+		n = 63
+	}
+	r := lmrReductions[depth][n]
+	if r > depth-2 {
+		r = depth - 2
+	}
+	if r < 0 {
+		r = 0
+	}
+	return r
+}
+
+// nullMoveReduction returns the depth reduction R of a null move search.
+func nullMoveReduction(depth int) int {
+	return 2 + depth/4
+}
+
+// lateMoveCount returns the number of quiet moves that are searched at the given depth
+// before late move pruning skips the rest.
+func lateMoveCount(depth int) int {
+	return 3 + depth*depth
+}
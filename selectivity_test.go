@@ -0,0 +1,76 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestSelectivityOptions tests that every technique can be switched by its option.
+func TestSelectivityOptions(t *testing.T) {
+	engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+	if engine.selectivity != FULL_SELECTIVITY {
+		t.Fatalf("Expected all techniques to be enabled by default\n")
+	}
+
+	options := map[string]func(s Selectivity) bool{
+		"NullMove":        func(s Selectivity) bool { return s.NullMove },
+		"LMR":             func(s Selectivity) bool { return s.LMR },
+		"ReverseFutility": func(s Selectivity) bool { return s.ReverseFutility },
+		"Futility":        func(s Selectivity) bool { return s.Futility },
+		"LateMovePruning": func(s Selectivity) bool { return s.LateMovePruning },
+	}
+	for name, flag := range options {
+		if err := engine.SetOption(name, "false"); err != nil {
+			t.Fatalf("Option %s: %s\n", name, err)
+		}
+		if flag(engine.selectivity) {
+			t.Fatalf("Expected option %s to disable its technique\n", name)
+		}
+	}
+	if engine.selectivity != (Selectivity{}) {
+		t.Fatalf("Expected all techniques to be disabled but got %+v\n", engine.selectivity)
+	}
+}
+
+// TestSelectiveSearch tests that the search finds mates and does not lose material
+// with each technique alone and with all of them.
+func TestSelectiveSearch(t *testing.T) {
+	type set struct {
+		Fen   string
+		Depth int
+		// Lowest score expected for the player to move.
+		Score int
+	}
+	testsets := []set{
+		// Back rank mate in 1.
+		{"6k1/5ppp/8/8/8/8/8/R5K1 w - - 0 1", 4, MATE_SCORE - 1},
+		// Rook ladder mate in 2.
+		{"7k/8/R7/8/8/8/8/1R5K w - - 0 1", 5, MATE_SCORE - 3},
+		// Taking the defended pawn loses the queen.
+		{"4k3/8/3p4/4p3/8/8/8/4QK2 w - - 0 1", 5, 500},
+		// A pawn ending where white must not lose the pawn.
+		{"4k3/8/3KP3/8/8/8/8/8 w - - 0 1", 9, 50},
+	}
+	selectivities := []Selectivity{
+		{NullMove: true},
+		{LMR: true},
+		{ReverseFutility: true},
+		{Futility: true},
+		{LateMovePruning: true},
+		FULL_SELECTIVITY,
+	}
+
+	for _, sel := range selectivities {
+		for _, set := range testsets {
+			engine := NewEngine("test", "test", nil, ALPHA_BETA_SEARCH)
+			engine.selectivity = sel
+			if err := engine.board.SetFEN(set.Fen); err != nil {
+				t.Fatalf(err.Error())
+			}
+			dostop := uint32(0)
+			sr := AlphaBetaSearch(engine, &SearchSettings{MaxDepth: set.Depth}, &dostop)
+			if sr.Score < set.Score {
+				t.Fatalf("Expected a score of at least %d for FEN %s with %+v but got %d with %s\n", set.Score, set.Fen, sel, sr.Score, sr.Move.MiniNotation())
+			}
+		}
+	}
+}
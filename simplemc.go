@@ -2,28 +2,43 @@ package chesskimo
 
 import (
 	"math/rand"
-	"time"
+	"sync/atomic"
 )
 
-// SimpleMCSearch runs a simple random simulation (monte carlo) for a
-// given time and returns the best move.
+// SimpleMCSearch runs a simple random simulation (monte carlo) until the deadline
+// or the node limit of the search settings and returns the best move.
+// Every simulation counts as a node.
 func SimpleMCSearch(engine *Engine, ss *SearchSettings, dostop *uint32) SearchResult {
-	startTime := time.Now()
-	board := &engine.board
+	tm := engine.newTimeManager(ss)
+	if !ss.Infinite && ss.Nodes == 0 {
+		// The simulations have no depth and cannot prove a mate, so a search
+		// limited by depth or mate ends after the default move time.
+		tm.fallback(DEFAULT_MOVE_TIME)
+	}
+	// The search works on its own copy of the board.
+	boardCopy := engine.board
+	board := &boardCopy
 	sr := SearchResult{Move: BitMove(0)}
 	player := board.Player
 	mlist := MoveList{}
 	workMlist := MoveList{}
 	workBoard := *board
 	simcount := uint64(0)
+	stopped := func() bool {
+		return atomic.LoadUint32(dostop) != 0 || tm.HardLimitReached() || (ss.Nodes > 0 && simcount >= ss.Nodes)
+	}
 
 	// Find all possible first moves.
 	board.GenerateAllLegalMoves(&mlist)
+	if mlist.Size == 0 {
+		// Checkmate or stalemate -> there is nothing to simulate.
+		return sr
+	}
 	scores := make([]int64, mlist.Size)
+	// Even if the search is stopped before the first simulation we have a legal move.
+	sr.Move = mlist.Moves[0]
 
-	maxtime := 10.0
-
-	for /*atomic.LoadUint32(dostop) == 0 &&*/ time.Since(startTime).Seconds() < maxtime {
+	for !stopped() {
 		for i := uint32(0); i < mlist.Size; i++ {
 			move := mlist.Moves[i]
 			//			engine.logger.Println("Simulation for move ", move.MiniNotation())
@@ -36,26 +51,16 @@ func SimpleMCSearch(engine *Engine, ss *SearchSettings, dostop *uint32) SearchRe
 				workMlist.Clear()
 				workBoard.GenerateAllLegalMoves(&workMlist)
 				//				engine.logger.Println("genall: ", workMlist.String())
-				// e2e4 h7h5 d2d4
-				if workBoard.MoveNumber > 150 {
-					// artificial limit -> draw
-					break
-				} else if workMlist.Size == 0 {
-					// No moves for current player
-					if workBoard.CheckInfo == CHECK_NONE {
-						// No check -> stalemate
-						break
-					} else {
-						// Check -> checkmate
-						if workBoard.Player != player {
-							scores[i] += 1
-						} else {
-							scores[i] += -1
-						}
-						break
+				// 2. Stop if the game has ended.
+				if state, _ := workBoard.Result(&workMlist); state != GAMESTATE_ONGOING {
+					if state == State(player) {
+						scores[i] += 1
+					} else if state != GAMESTATE_DRAW {
+						scores[i] += -1
 					}
+					break
 				}
-				// 2. Make random move
+				// 3. Make random move
 				r := rand.Intn(int(workMlist.Size))
 				//				engine.logger.Printf("MAKE MOVE %s", workMlist.Moves[r].MiniNotation())
 				//				engine.logger.Print(workBoard.InfoBoardString())
@@ -63,13 +68,13 @@ func SimpleMCSearch(engine *Engine, ss *SearchSettings, dostop *uint32) SearchRe
 			}
 			simcount++
 
-			if time.Since(startTime).Seconds() >= maxtime {
+			if stopped() {
 				break
 			}
 		}
 	}
 
-	engine.logger.Printf("Time used: %f sec. Simulations run %d.", time.Since(startTime).Seconds(), simcount)
+	engine.logger.Printf("Time used: %f sec. Simulations run %d.", tm.Elapsed().Seconds(), simcount)
 
 	bestscore := -int64(simcount)
 	// Find best move and log data.
@@ -81,6 +86,9 @@ func SimpleMCSearch(engine *Engine, ss *SearchSettings, dostop *uint32) SearchRe
 		}
 		engine.logger.Printf("Move %s has score %d", mlist.Moves[i].MiniNotation(), score)
 	}
+	// The simulations do not yield a line, only the best move.
+	sr.PV = []BitMove{sr.Move}
+	sr.Nodes, sr.Time = simcount, tm.Elapsed()
 
 	return sr
 }
@@ -0,0 +1,100 @@
+package chesskimo
+
+import (
+	"time"
+)
+
+const (
+	// DEFAULT_MOVE_TIME is used for searches without any limit.
+	DEFAULT_MOVE_TIME = 10 * time.Second
+	// MOVE_OVERHEAD is reserved on every move for the communication with the GUI.
+	MOVE_OVERHEAD = 30 * time.Millisecond
+	// MIN_CLOCK_TIME is the remaining time assumed for a clock that has run out.
+	MIN_CLOCK_TIME = time.Millisecond
+
+	// If the GUI does not tell the moves until the next time control,
+	// the remaining time is distributed over 'default_moves_to_go' moves.
+	default_moves_to_go = 30
+)
+
+// TimeManager turns the clock state of a search into deadlines.
+// The soft deadline tells the search not to start another iteration,
+// the hard deadline tells it to stop immediately.
+type TimeManager struct {
+	start   time.Time
+	soft    time.Duration
+	hard    time.Duration
+	limited bool
+}
+
+// NewTimeManager calculates the deadlines of a search for the player 'color'.
+func NewTimeManager(ss *SearchSettings, color Color) TimeManager {
+	tm := TimeManager{start: time.Now()}
+
+	timeLeft, inc := ss.BTime, ss.BInc
+	if color == WHITE {
+		timeLeft, inc = ss.WTime, ss.WInc
+	}
+
+	switch {
+	case ss.Infinite:
+		// Only a stop command ends the search.
+	case ss.MoveTime > 0:
+		tm.limited = true
+		tm.hard = ss.MoveTime - MOVE_OVERHEAD
+		if tm.hard < ss.MoveTime/2 {
+			tm.hard = ss.MoveTime / 2
+		}
+		tm.soft = tm.hard
+	case timeLeft > 0:
+		tm.limited = true
+		available := timeLeft - MOVE_OVERHEAD
+		if available < timeLeft/2 {
+			available = timeLeft / 2
+		}
+		movesToGo := ss.MovesToGo
+		if movesToGo <= 0 || movesToGo > default_moves_to_go {
+			movesToGo = default_moves_to_go
+		}
+
+		tm.soft = available/time.Duration(movesToGo) + inc*3/4
+		tm.hard = tm.soft * 4
+		// Never spend most of the remaining time on a single move.
+		if limit := available * 4 / 5; tm.hard > limit {
+			tm.hard = limit
+		}
+		if tm.soft > tm.hard {
+			tm.soft = tm.hard
+		}
+	case ss.MaxDepth > 0 || ss.Nodes > 0 || ss.Mate > 0:
+		// The search ends by its own limits.
+	default:
+		tm.fallback(DEFAULT_MOVE_TIME)
+	}
+
+	return tm
+}
+
+// fallback sets both deadlines to d if the search has no time limit.
+func (tm *TimeManager) fallback(d time.Duration) {
+	if !tm.limited {
+		tm.limited = true
+		tm.soft = d
+		tm.hard = d
+	}
+}
+
+// Elapsed returns the time since the search started.
+func (tm *TimeManager) Elapsed() time.Duration {
+	return time.Since(tm.start)
+}
+
+// SoftLimitReached returns true if no new iteration should be started.
+func (tm *TimeManager) SoftLimitReached() bool {
+	return tm.limited && tm.Elapsed() >= tm.soft
+}
+
+// HardLimitReached returns true if the search must stop immediately.
+func (tm *TimeManager) HardLimitReached() bool {
+	return tm.limited && tm.Elapsed() >= tm.hard
+}
@@ -0,0 +1,52 @@
+package chesskimo
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTimeManager tests the deadlines for different search settings.
+func TestTimeManager(t *testing.T) {
+	type set struct {
+		Settings SearchSettings
+		Color    Color
+		Limited  bool
+		Soft     time.Duration
+		Hard     time.Duration
+	}
+	testsets := []set{
+		{SearchSettings{Infinite: true, WTime: time.Minute}, WHITE, false, 0, 0},
+		{SearchSettings{MaxDepth: 5}, WHITE, false, 0, 0},
+		{SearchSettings{}, WHITE, true, DEFAULT_MOVE_TIME, DEFAULT_MOVE_TIME},
+		{SearchSettings{MoveTime: time.Second}, BLACK, true, time.Second - MOVE_OVERHEAD, time.Second - MOVE_OVERHEAD},
+		// 60s - 30ms overhead over 30 moves plus 3/4 of the increment.
+		{SearchSettings{WTime: 60 * time.Second, WInc: time.Second, BTime: time.Second}, WHITE, true, 2749 * time.Millisecond, 10996 * time.Millisecond},
+		// The hard limit never exceeds 4/5 of the remaining time.
+		{SearchSettings{BTime: 10 * time.Second, MovesToGo: 1}, BLACK, true, 7976 * time.Millisecond, 7976 * time.Millisecond},
+		// A clock that has run out still limits the search.
+		{SearchSettings{WTime: MIN_CLOCK_TIME}, WHITE, true, 16666, 66664},
+	}
+
+	for i, ts := range testsets {
+		tm := NewTimeManager(&ts.Settings, ts.Color)
+		if tm.limited != ts.Limited || tm.soft != ts.Soft || tm.hard != ts.Hard {
+			t.Fatalf("Test %d: expected limited=%v soft=%v hard=%v but got limited=%v soft=%v hard=%v\n",
+				i, ts.Limited, ts.Soft, ts.Hard, tm.limited, tm.soft, tm.hard)
+		}
+	}
+}
+
+// TestTimeManagerFallback tests if the fallback deadline only applies to searches without a time limit.
+func TestTimeManagerFallback(t *testing.T) {
+	tm := NewTimeManager(&SearchSettings{MaxDepth: 5}, WHITE)
+	tm.fallback(time.Second)
+	if !tm.limited || tm.soft != time.Second || tm.hard != time.Second {
+		t.Fatalf("Expected the fallback deadline but got limited=%v soft=%v hard=%v\n", tm.limited, tm.soft, tm.hard)
+	}
+
+	tm = NewTimeManager(&SearchSettings{MoveTime: 100 * time.Millisecond}, WHITE)
+	tm.fallback(time.Second)
+	if tm.hard != 100*time.Millisecond-MOVE_OVERHEAD {
+		t.Fatalf("Expected the move time deadline but got %v\n", tm.hard)
+	}
+}
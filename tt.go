@@ -0,0 +1,176 @@
+package chesskimo
+
+import (
+	"unsafe"
+)
+
+const (
+	// Bound types of a transposition table entry.
+	BOUND_NONE  uint8 = 0
+	BOUND_EXACT uint8 = 1
+	BOUND_LOWER uint8 = 2 // The score is at least this high (fail-high).
+	BOUND_UPPER uint8 = 3 // The score is at most this high (fail-low).
+
+	// Default, minimum and maximum size of the transposition table in MB.
+	TT_DEFAULT_MB = 16
+	TT_MIN_MB     = 1
+	TT_MAX_MB     = 4096
+)
+
+// TTEntry is a single entry of the transposition table.
+type TTEntry struct {
+	Key   uint64
+	Move  BitMove
+	Score int32
+	Depth int8
+	Bound uint8
+	Age   uint8
+}
+
+// ttBucket holds two entries. The first one is only replaced by searches of
+// equal or higher depth (or by newer searches), the second one is always replaced.
+type ttBucket struct {
+	deep   TTEntry
+	recent TTEntry
+}
+
+// TranspositionTable is a fixed size hash table which stores search results by Zobrist key.
+type TranspositionTable struct {
+	buckets []ttBucket
+	mask    uint64
+	age     uint8
+}
+
+// NewTranspositionTable creates a transposition table that uses at most mb megabytes.
+func NewTranspositionTable(mb int) *TranspositionTable {
+	tt := &TranspositionTable{}
+	tt.Resize(mb)
+	return tt
+}
+
+// Resize reallocates the table for a new size in megabytes. All entries are lost.
+func (tt *TranspositionTable) Resize(mb int) {
+	if mb < TT_MIN_MB {
+		mb = TT_MIN_MB
+	} else if mb > TT_MAX_MB {
+		mb = TT_MAX_MB
+	}
+
+	// The number of buckets must be a power of two, so the key can be masked.
+	count := uint64(mb) * 1024 * 1024 / uint64(unsafe.Sizeof(ttBucket{}))
+	size := uint64(1)
+	for size*2 <= count {
+		size *= 2
+	}
+
+	tt.buckets = make([]ttBucket, size)
+	tt.mask = size - 1
+	tt.age = 0
+}
+
+// Clear removes all entries from the table.
+func (tt *TranspositionTable) Clear() {
+	for i := range tt.buckets {
+		tt.buckets[i] = ttBucket{}
+	}
+	tt.age = 0
+}
+
+// NewSearch must be called before every search. Entries of older searches
+// are then preferred for replacement.
+func (tt *TranspositionTable) NewSearch() {
+	tt.age++
+}
+
+// Probe looks up the entry for key. The second return value is false if there is none.
+// The score of the entry is stored relative to the node, use ScoreFromTT to read it.
+func (tt *TranspositionTable) Probe(key uint64) (TTEntry, bool) {
+	bucket := &tt.buckets[key&tt.mask]
+	if bucket.deep.Key == key && bucket.deep.Bound != BOUND_NONE {
+		return bucket.deep, true
+	}
+	if bucket.recent.Key == key && bucket.recent.Bound != BOUND_NONE {
+		return bucket.recent, true
+	}
+
+	return TTEntry{}, false
+}
+
+// Store saves a search result for key. The score is relative to the root
+// and is converted with the given ply so mate scores stay correct when the
+// entry is found at a different distance from the root.
+func (tt *TranspositionTable) Store(key uint64, move BitMove, score, depth, ply int, bound uint8) {
+	bucket := &tt.buckets[key&tt.mask]
+	entry := TTEntry{
+		Key:   key,
+		Move:  move,
+		Score: int32(ScoreToTT(score, ply)),
+		Depth: int8(depth),
+		Bound: bound,
+		Age:   tt.age,
+	}
+
+	deep := &bucket.deep
+	if deep.Key == key || deep.Age != tt.age || int(deep.Depth) <= depth {
+		if move == BitMove(0) && deep.Key == key {
+			// Keep the best move of a previous search of this position.
+			entry.Move = deep.Move
+		} else if move == BitMove(0) && bucket.recent.Key == key {
+			entry.Move = bucket.recent.Move
+		}
+		if deep.Key != key && deep.Bound != BOUND_NONE {
+			// The replaced entry is still the most recent result of its position.
+			bucket.recent = *deep
+		}
+		*deep = entry
+		return
+	}
+
+	if move == BitMove(0) && bucket.recent.Key == key {
+		entry.Move = bucket.recent.Move
+	}
+	bucket.recent = entry
+}
+
+// Hashfull returns how many of the entries (per mille) are used by the current search.
+// Only a sample at the start of the table is inspected.
+func (tt *TranspositionTable) Hashfull() int {
+	sample := 500
+	if sample > len(tt.buckets) {
+		sample = len(tt.buckets)
+	}
+	used := 0
+	for i := 0; i < sample; i++ {
+		bucket := &tt.buckets[i]
+		if bucket.deep.Bound != BOUND_NONE && bucket.deep.Age == tt.age {
+			used++
+		}
+		if bucket.recent.Bound != BOUND_NONE && bucket.recent.Age == tt.age {
+			used++
+		}
+	}
+
+	return used * 1000 / (2 * sample)
+}
+
+// ScoreToTT converts a score relative to the root into a score relative to
+// the node at the given ply. Only mate scores are affected.
+func ScoreToTT(score, ply int) int {
+	if score >= MATE_BOUND {
+		return score + ply
+	} else if score <= -MATE_BOUND {
+		return score - ply
+	}
+	return score
+}
+
+// ScoreFromTT converts a score read from the table back into a score
+// relative to the root for a node at the given ply.
+func ScoreFromTT(score, ply int) int {
+	if score >= MATE_BOUND {
+		return score - ply
+	} else if score <= -MATE_BOUND {
+		return score + ply
+	}
+	return score
+}
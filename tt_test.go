@@ -0,0 +1,84 @@
+package chesskimo
+
+import (
+	"testing"
+)
+
+// TestTranspositionTable tests storing, probing and the replacement scheme.
+func TestTranspositionTable(t *testing.T) {
+	tt := NewTranspositionTable(1)
+	size := uint64(len(tt.buckets))
+	move := NewBitMove(0x14, 0x34, NONE)
+
+	if _, ok := tt.Probe(0x1234); ok {
+		t.Fatalf("Empty table should not contain an entry\n")
+	}
+
+	tt.Store(0x1234, move, 42, 5, 0, BOUND_EXACT)
+	entry, ok := tt.Probe(0x1234)
+	if !ok || entry.Move != move || entry.Score != 42 || entry.Depth != 5 || entry.Bound != BOUND_EXACT {
+		t.Fatalf("Stored entry was not found: %v\n", entry)
+	}
+
+	// A shallower entry for another key in the same bucket goes to the always-replace slot.
+	other := 0x1234 + size
+	tt.Store(other, move, 7, 2, 0, BOUND_LOWER)
+	if _, ok := tt.Probe(0x1234); !ok {
+		t.Fatalf("Deep entry was replaced by a shallower one\n")
+	}
+	if _, ok := tt.Probe(other); !ok {
+		t.Fatalf("Shallow entry was not stored\n")
+	}
+
+	// A third key replaces the always-replace slot.
+	third := 0x1234 + 2*size
+	tt.Store(third, move, 7, 1, 0, BOUND_UPPER)
+	if _, ok := tt.Probe(other); ok {
+		t.Fatalf("Always-replace slot was not replaced\n")
+	}
+
+	// A deeper entry takes the depth-preferred slot, the replaced entry moves to the always-replace slot.
+	tt.Store(other, move, 7, 6, 0, BOUND_LOWER)
+	if entry, ok := tt.Probe(other); !ok || entry.Depth != 6 {
+		t.Fatalf("Deeper entry was not stored: %v\n", entry)
+	}
+	if entry, ok := tt.Probe(0x1234); !ok || entry.Depth != 5 {
+		t.Fatalf("Replaced deep entry was not moved to the always-replace slot: %v\n", entry)
+	}
+	if _, ok := tt.Probe(third); ok {
+		t.Fatalf("Always-replace slot was not replaced\n")
+	}
+
+	// Entries of older searches are replaced.
+	tt.NewSearch()
+	tt.Store(third, move, 7, 1, 0, BOUND_LOWER)
+	if entry, ok := tt.Probe(third); !ok || entry.Age != tt.age || tt.buckets[third&tt.mask].deep.Key != third {
+		t.Fatalf("Deep entry of an old search was not replaced\n")
+	}
+	if _, ok := tt.Probe(0x1234); ok {
+		t.Fatalf("Always-replace slot was not replaced\n")
+	}
+
+	tt.Clear()
+	if _, ok := tt.Probe(other); ok {
+		t.Fatalf("Cleared table should not contain an entry\n")
+	}
+}
+
+// TestMateScoreTT tests if mate scores are stored relative to the node.
+func TestMateScoreTT(t *testing.T) {
+	// A mate in 3 plies found at ply 2 is a mate in 1 ply from that node.
+	score := MATE_SCORE - 3
+	stored := ScoreToTT(score, 2)
+	if stored != MATE_SCORE-1 {
+		t.Fatalf("Stored mate score should be %d but is %d\n", MATE_SCORE-1, stored)
+	}
+	// Found again at ply 4 it is a mate in 5 plies from the root.
+	if s := ScoreFromTT(stored, 4); s != MATE_SCORE-5 {
+		t.Fatalf("Read mate score should be %d but is %d\n", MATE_SCORE-5, s)
+	}
+	// Normal scores are not changed.
+	if ScoreToTT(-150, 7) != -150 || ScoreFromTT(150, 7) != 150 {
+		t.Fatalf("Normal scores must not be adjusted\n")
+	}
+}
@@ -5,18 +5,87 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
 )
 
+// UCI implements the Communicator interface for the Universal Chess Interface protocol.
+// Commands are read from In and answers are written to Out. If they are nil,
+// stdin and stdout are used.
 type UCI struct {
-	newGame bool
+	In  io.Reader
+	Out io.Writer
+
+	// outMutex serializes the output of the input loop and the search goroutine.
+	outMutex sync.Mutex
+}
+
+// send writes one line to the output.
+func (u *UCI) send(args ...interface{}) {
+	u.outMutex.Lock()
+	defer u.outMutex.Unlock()
+	fmt.Fprintln(u.Out, args...)
+}
+
+// SendInfo sends the progress of a search as 'info' line.
+func (u *UCI) SendInfo(info SearchInfo) {
+	if info.String != "" {
+		u.send("info string", info.String)
+		return
+	}
+
+	var sb strings.Builder
+	sb.WriteString("info")
+	if info.Depth > 0 {
+		sb.WriteString(" depth " + strconv.Itoa(info.Depth))
+	}
+	if info.SelDepth > 0 {
+		sb.WriteString(" seldepth " + strconv.Itoa(info.SelDepth))
+	}
+	if info.MultiPV > 0 {
+		sb.WriteString(" multipv " + strconv.Itoa(info.MultiPV))
+	}
+	if len(info.PV) > 0 {
+		sb.WriteString(" score " + uciScore(info.Score))
+	}
+	millis := info.Time.Milliseconds()
+	sb.WriteString(" nodes " + strconv.FormatUint(info.Nodes, 10))
+	if millis > 0 {
+		sb.WriteString(" nps " + strconv.FormatUint(info.Nodes*1000/uint64(millis), 10))
+	}
+	sb.WriteString(" time " + strconv.FormatInt(millis, 10))
+	sb.WriteString(" hashfull " + strconv.Itoa(info.HashFull))
+	if len(info.PV) > 0 {
+		sb.WriteString(" pv")
+		for _, m := range info.PV {
+			sb.WriteString(" " + m.MiniNotation())
+		}
+	}
+
+	u.send(sb.String())
+}
+
+// uciScore formats a score as 'cp <centipawns>' or 'mate <moves>'.
+// Negative mate values mean that the engine is getting mated.
+func uciScore(score int) string {
+	if score >= MATE_BOUND {
+		return "mate " + strconv.Itoa((MATE_SCORE-score+1)/2)
+	} else if score <= -MATE_BOUND {
+		return "mate " + strconv.Itoa(-(MATE_SCORE+score)/2)
+	}
+	return "cp " + strconv.Itoa(score)
 }
 
 func (u *UCI) RunInputOutputLoop(engine *Engine) {
-	reader := bufio.NewReader(os.Stdin)
-	if reader == nil {
-		panic("Cannot read from std in.")
+	if u.In == nil {
+		u.In = os.Stdin
 	}
+	if u.Out == nil {
+		u.Out = os.Stdout
+	}
+	reader := bufio.NewReader(u.In)
 
 	for {
 		command, err := reader.ReadString('\n')
@@ -25,18 +94,21 @@ func (u *UCI) RunInputOutputLoop(engine *Engine) {
 			break
 		} else if err == nil && len(command) > 0 {
 			// Split input string into command parts.
-			input := strings.Split(strings.Trim(command, " \t\r\n"), " ")
+			input := strings.Fields(command)
+			if len(input) == 0 {
+				continue
+			}
 			cmd := input[0]
 			switch cmd {
 			case "quit":
-				// TODO -> shutdown engine.
-				os.Exit(0)
+				engine.Quit()
+				return
 			case "uci":
 				// Enable UCI mode and identify yourself.
 				u.cmdUci(engine)
 			case "isready":
 				// Check if engine can receive commands/is active.
-				u.cmdIsready()
+				u.cmdIsready(engine)
 			case "ucinewgame":
 				u.cmdNewGame(engine)
 			case "position":
@@ -45,19 +117,95 @@ func (u *UCI) RunInputOutputLoop(engine *Engine) {
 				u.cmdGo(engine, input[1:])
 			case "stop":
 				u.cmdStop(engine)
+			case "setoption":
+				u.cmdSetOption(engine, input[1:])
+			case "debug":
+				u.cmdDebug(engine, input[1:])
+			case "d":
+				// Non-standard debug command: display the current position.
+				u.cmdDisplay(engine)
 			}
 		}
 	}
 
-	// TODO -> shutdown engine.
-	os.Exit(0)
+	// Input was closed.
+	engine.Quit()
+}
+
+func (u *UCI) cmdSetOption(engine *Engine, args []string) {
+	// Expected format: name <id> [value <x>]
+	// Both the name and the value may contain spaces.
+	if len(args) < 2 || args[0] != "name" {
+		engine.logger.Print("*** invalid setoption: ", args)
+		return
+	}
+
+	name, value := args[1:], []string{}
+	for i, arg := range name {
+		if arg == "value" {
+			name, value = name[:i], name[i+1:]
+			break
+		}
+	}
+
+	err := engine.SetOption(strings.Join(name, " "), strings.Join(value, " "))
+	if err != nil {
+		engine.logger.Print("*** setoption failed: ", err)
+		u.send("info string setoption", strings.Join(args[1:], " ")+":", err.Error())
+	}
+}
+
+func (u *UCI) cmdDebug(engine *Engine, args []string) {
+	// Expected format: debug [on | off]
+	if len(args) == 0 || (args[0] != "on" && args[0] != "off") {
+		engine.logger.Print("*** invalid debug: ", args)
+		return
+	}
+	engine.SetDebug(args[0] == "on")
 }
 
 func (u *UCI) cmdStop(engine *Engine) {
-	// TODO stop engine
+	// Stopping waits until the search has sent its best move.
+	engine.StopSearch()
 }
 
 func (u *UCI) cmdGo(engine *Engine, args []string) {
+	ts := SearchSettings{}
+
+	// nextInt consumes the value of the current parameter.
+	nextInt := func() int {
+		if len(args) == 0 {
+			return 0
+		}
+		n, err := strconv.Atoi(args[0])
+		args = args[1:]
+		if err != nil || n < 0 {
+			engine.logger.Print("*** invalid go parameter value: ", err)
+			return 0
+		}
+		return n
+	}
+	millis := func() time.Duration {
+		return time.Duration(nextInt()) * time.Millisecond
+	}
+	// clock consumes a remaining time. Some GUIs send 0 or a negative time when the
+	// flag has fallen. It is not dropped, 0 would mean that there is no clock.
+	clock := func() time.Duration {
+		if len(args) == 0 {
+			return 0
+		}
+		n, err := strconv.Atoi(args[0])
+		args = args[1:]
+		if err != nil {
+			engine.logger.Print("*** invalid go parameter value: ", err)
+			return 0
+		}
+		if d := time.Duration(n) * time.Millisecond; d > MIN_CLOCK_TIME {
+			return d
+		}
+		return MIN_CLOCK_TIME
+	}
+
 	for len(args) > 0 {
 		cmd := args[0]
 		args = args[1:]
@@ -65,108 +213,108 @@ func (u *UCI) cmdGo(engine *Engine, args []string) {
 		case "searchmoves":
 		case "ponder":
 		case "wtime":
+			ts.WTime = clock()
 		case "btime":
+			ts.BTime = clock()
 		case "winc":
+			ts.WInc = millis()
 		case "binc":
+			ts.BInc = millis()
 		case "movestogo":
+			ts.MovesToGo = nextInt()
 		case "depth":
+			ts.MaxDepth = nextInt()
 		case "nodes":
+			ts.Nodes = uint64(nextInt())
 		case "mate":
+			ts.Mate = nextInt()
 		case "movetime":
+			ts.MoveTime = millis()
 		case "infinite":
+			ts.Infinite = true
 		}
 	}
 
-	//	moves := engine.GetLegalMoves()
-	//	r := rand.Intn(int(moves.Size))
-	//	bm := moves.Moves[r]
-	ts := SearchSettings{}
-	dostop := uint32(0)
-	sr := engine.search(engine, &ts, &dostop)
-	engine.logger.Println("--> best move:", sr.Move.MiniNotation())
-	engine.board.MakeLegalMove(sr.Move)
-	engine.logger.Print(engine.board.String())
-	fmt.Println("bestmove", sr.Move.MiniNotation())
+	engine.StartSearch(ts, func(sr SearchResult) {
+		engine.logger.Println("--> best move:", sr.Move.MiniNotation())
+		if sr.Move == BitMove(0) {
+			// No legal move exists. UCI expects a null move in this case.
+			u.send("bestmove", "0000")
+			return
+		}
+		if len(sr.PV) > 1 {
+			// The expected reply of the opponent is the move to ponder on.
+			u.send("bestmove", sr.Move.MiniNotation(), "ponder", sr.PV[1].MiniNotation())
+			return
+		}
+		u.send("bestmove", sr.Move.MiniNotation())
+	})
 }
 
 func (u *UCI) cmdPosition(engine *Engine, args []string) {
-	if len(args) > 1 {
-		if !u.newGame {
-			// Continue ongoing game by just extracting the last move.
-			engine.logger.Print("*** continue ongoing game")
-			last := args[len(args)-1]
-			err := engine.MakeMove(last)
-			if err != nil {
-				// UCI is crap.
-				engine.logger.Print("*** move ", last, " impossible: ", err.Error())
-			}
-		} else {
-			first := args[0]
-			if first == "startpos" {
-				args = args[1:]
-				engine.board.SetStartingPosition()
-			} else {
-				if first == "fen" {
-					// Some frontends say "position fen" then specify the actual fen, some specify
-					// the actual fen directly after "position", so we have to check both ways..
-					// no wonder with this as official doc: http://wbec-ridderkerk.nl/html/UCIProtocol.html
-					// *sigh*
-					args = args[1:]
-					if len(args) > 0 {
-						first = args[0]
-					} else {
-						return // Invalid.. missing arguments
-					}
-				}
-
-				fen := ""
-				// We now have to concat all FEN parts.
-				for i := 0; i < len(args); i++ {
-					if args[i] != "moves" {
-						// TODO - remove all quotes... just to be sure.
-						fen += args[i] + " "
-					} else {
-						// Finished FEN.. discard fen arguments.
-						args = args[i:]
-					}
-				}
-				err := engine.board.SetFEN(fen)
-				if err != nil {
-					fmt.Println("--> error: ", err.Error())
-					return // UCI ignores bad commands.
-				}
-			}
+	// Expected format: [startpos | fen <fen> | <fen>] [moves <move1> ... <moveN>]
+	// The board is always set up from scratch, so the engine does not depend
+	// on previous position commands.
+	if len(args) == 0 {
+		u.send("info string position: missing arguments")
+		return
+	}
 
-			// Last check for "moves" subcommand.
-			// Must at least have length 2 (including a move).
-			if len(args) > 1 && args[0] == "moves" {
-				moves := args[1:]
-				for _, m := range moves {
-					err := engine.MakeMove(m)
-					if err != nil {
-						// UCI is crap.
-						engine.logger.Print("*** move ", m, " impossible: ", err.Error())
-					}
-				}
-			}
+	var moves []string
+	for i, arg := range args {
+		if arg == "moves" {
+			args, moves = args[:i], args[i+1:]
+			break
 		}
-		u.newGame = false
 	}
+
+	fen := ""
+	if len(args) > 0 && args[0] == "startpos" {
+		if len(args) > 1 {
+			u.send("info string position: unexpected arguments after startpos:", strings.Join(args[1:], " "))
+			return
+		}
+	} else {
+		// Some frontends say "position fen" then specify the actual fen, some specify
+		// the actual fen directly after "position", so we have to check both ways.
+		if len(args) > 0 && args[0] == "fen" {
+			args = args[1:]
+		}
+		fen = strings.Trim(strings.Join(args, " "), "\"'")
+		if fen == "" {
+			u.send("info string position: missing fen")
+			return
+		}
+	}
+
+	if err := engine.SetPosition(fen, moves); err != nil {
+		engine.logger.Print("*** invalid position: ", err)
+		u.send("info string position:", err.Error())
+		return
+	}
+	engine.logger.Print("*** position: ", engine.board.FEN())
+}
+
+func (u *UCI) cmdDisplay(engine *Engine) {
+	engine.StopSearch()
+	u.send(strings.TrimRight(engine.board.String(), "\n"))
+	u.send("Fen:", engine.board.FEN())
 }
 
 func (u *UCI) cmdNewGame(engine *Engine) {
-	u.newGame = true
 	engine.NewGame()
 }
 
-func (u *UCI) cmdIsready() {
-	// TODO wait for engine ?
-	fmt.Println("readyok")
+func (u *UCI) cmdIsready(engine *Engine) {
+	engine.IsReady()
+	u.send("readyok")
 }
 
 func (u *UCI) cmdUci(engine *Engine) {
-	fmt.Println("id name", engine.name)
-	fmt.Println("id author", engine.author)
-	// TODO -> add all possible options here.
-	fmt.Println("uciok")
+	u.send("id name", engine.name)
+	u.send("id author", engine.author)
+	for _, o := range engine.Options() {
+		u.send(o.UCIString())
+	}
+	u.send("uciok")
 }
@@ -0,0 +1,191 @@
+package chesskimo
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// syncBuffer is a buffer that may be written by the search goroutine while the test reads it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (b *syncBuffer) Write(p []byte) (int, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.Write(p)
+}
+
+func (b *syncBuffer) String() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.buf.String()
+}
+
+// runUCI runs the UCI loop with the given input and returns all output lines.
+func runUCI(input string, search NamedSearch) []string {
+	out := &bytes.Buffer{}
+	uci := &UCI{In: strings.NewReader(input), Out: out}
+	engine := NewEngine("test", "test", uci, search)
+	uci.RunInputOutputLoop(engine)
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+// runUCISearch runs the UCI loop with the given input, which must start a search.
+// Quit is only sent after the best move, so the search is not interrupted.
+// It returns all output lines.
+func runUCISearch(t *testing.T, input string, search NamedSearch, timeout time.Duration) []string {
+	in, w := io.Pipe()
+	out := &syncBuffer{}
+	uci := &UCI{In: in, Out: out}
+	engine := NewEngine("test", "test", uci, search)
+	done := make(chan struct{})
+	go func() {
+		uci.RunInputOutputLoop(engine)
+		close(done)
+	}()
+
+	io.WriteString(w, input)
+	deadline := time.Now().Add(timeout)
+	for !strings.Contains(out.String(), "bestmove ") {
+		if time.Now().After(deadline) {
+			w.Close()
+			<-done
+			t.Fatalf("Expected a best move but got:\n%s\n", out.String())
+		}
+		time.Sleep(time.Millisecond)
+	}
+	io.WriteString(w, "quit\n")
+	w.Close()
+	<-done
+
+	return strings.Split(strings.TrimSpace(out.String()), "\n")
+}
+
+// TestUCIStop tests if stop interrupts an infinite search and a best move is sent.
+func TestUCIStop(t *testing.T) {
+	for _, search := range []NamedSearch{ALPHA_BETA_SEARCH, MONTE_CARLO_SEARCH} {
+		lines := runUCI("uci\nisready\nposition startpos\ngo infinite\nisready\nstop\nisready\nquit\n", search)
+
+		expected := []string{"uciok", "readyok", "readyok", "bestmove", "readyok"}
+		idx := 0
+		for _, line := range lines {
+			if idx < len(expected) && strings.HasPrefix(line, expected[idx]) {
+				idx++
+			}
+		}
+		if idx != len(expected) {
+			t.Fatalf("Expected output in order %v but got:\n%s\n", expected, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// TestUCIQuit tests if quit during a search still sends the best move and returns.
+func TestUCIQuit(t *testing.T) {
+	lines := runUCI("position startpos\ngo infinite\nquit\n", ALPHA_BETA_SEARCH)
+	last := lines[len(lines)-1]
+	if !strings.HasPrefix(last, "bestmove ") {
+		t.Fatalf("Expected a best move but got: %s\n", last)
+	}
+}
+
+// TestUCINegativeClock tests if a search with a fallen flag still moves at once.
+func TestUCINegativeClock(t *testing.T) {
+	runUCISearch(t, "position startpos\ngo wtime -120 btime -120\n", ALPHA_BETA_SEARCH, 2*time.Second)
+}
+
+// TestUCIMultiPV tests if every variation of a MultiPV search is reported.
+func TestUCIMultiPV(t *testing.T) {
+	lines := runUCISearch(t, "setoption name MultiPV value 3\nposition startpos\ngo depth 2\n", ALPHA_BETA_SEARCH, 10*time.Second)
+
+	found := map[string]bool{}
+	for _, line := range lines {
+		if strings.HasPrefix(line, "info depth 2 ") && strings.Contains(line, " pv ") {
+			fields := strings.Fields(line)
+			for i, f := range fields {
+				if f == "multipv" {
+					found[fields[i+1]] = true
+				}
+			}
+		}
+	}
+	if len(found) != 3 || !found["1"] || !found["2"] || !found["3"] {
+		t.Fatalf("Expected 3 variations at depth 2 but got:\n%s\n", strings.Join(lines, "\n"))
+	}
+}
+
+// TestUCICutoffRate tests if the search reports the share of cutoffs on the first move in debug mode only.
+func TestUCICutoffRate(t *testing.T) {
+	for _, debug := range []bool{true, false} {
+		input := "position startpos\ngo depth 4\n"
+		if debug {
+			input = "debug on\n" + input
+		}
+		lines := runUCISearch(t, input, ALPHA_BETA_SEARCH, 10*time.Second)
+
+		found := false
+		for i, line := range lines {
+			if strings.HasPrefix(line, "info string first move cutoffs ") {
+				if i+1 >= len(lines) || !strings.HasPrefix(lines[i+1], "bestmove ") {
+					t.Fatalf("Expected the rate right before the best move but got:\n%s\n", strings.Join(lines, "\n"))
+				}
+				found = true
+			}
+		}
+		if found != debug {
+			t.Fatalf("Expected the first move cutoff rate only in debug mode (debug %v) but got:\n%s\n", debug, strings.Join(lines, "\n"))
+		}
+	}
+}
+
+// TestUCIPosition tests if every position command sets up the board from scratch.
+func TestUCIPosition(t *testing.T) {
+	type set struct {
+		Input string
+		FEN   string
+		Error bool
+	}
+	testsets := []set{
+		{"position startpos moves e2e4 e7e5", "rnbqkbnr/pppp1ppp/8/4p3/4P3/8/PPPP1PPP/RNBQKBNR w KQkq e6 0 2", false},
+		// Take back a move.
+		{"position startpos moves e2e4 e7e5\nposition startpos moves e2e4", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", false},
+		{"position   fen  \"4k3/8/8/8/8/8/4P3/4K3 w - - 0 1\"   moves  e2e4", "4k3/8/8/8/4P3/8/8/4K3 b - e3 0 1", false},
+		{"position 4k3/4p3/8/8/8/8/8/4K3 b - - 0 1 moves e7e5", "4k3/8/8/4p3/8/8/8/4K3 w - e6 0 2", false},
+		// Errors keep the previous position.
+		{"position startpos moves e2e4\nposition startpos moves d2d4 e2e4x", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", true},
+		{"position startpos moves e2e4\nposition fen 8/8/8 w - - 0 1", "rnbqkbnr/pppppppp/8/8/4P3/8/PPPP1PPP/RNBQKBNR b KQkq e3 0 1", true},
+		{"position", "rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1", true},
+	}
+
+	for i, ts := range testsets {
+		lines := runUCI(ts.Input+"\nd\nquit\n", ALPHA_BETA_SEARCH)
+		if strings.HasPrefix(lines[0], "info string") != ts.Error {
+			t.Fatalf("Test %d: unexpected output:\n%s\n", i, strings.Join(lines, "\n"))
+		}
+		last := lines[len(lines)-1]
+		if last != "Fen: "+ts.FEN {
+			t.Fatalf("Test %d: expected 'Fen: %s' but got '%s'\n", i, ts.FEN, last)
+		}
+	}
+}
+
+// TestUCIPonder tests if the best move is sent together with the expected reply.
+func TestUCIPonder(t *testing.T) {
+	lines := runUCISearch(t, "position fen 7k/8/R7/8/8/8/8/1R5K w - - 0 1\ngo depth 4\n", ALPHA_BETA_SEARCH, 10*time.Second)
+
+	for _, line := range lines {
+		if strings.HasPrefix(line, "bestmove ") {
+			if line != "bestmove b1b7 ponder h8g8" && line != "bestmove a6a7 ponder h8g8" {
+				t.Fatalf("Expected a mating move and the reply h8g8 but got: %s\n", line)
+			}
+			return
+		}
+	}
+	t.Fatalf("Expected a best move but got:\n%s\n", strings.Join(lines, "\n"))
+}
@@ -0,0 +1,103 @@
+package chesskimo
+
+import (
+	"errors"
+	"math/bits"
+)
+
+var (
+	// ErrHashMismatch indicates that the incrementally updated hash of a board
+	// differs from the hash computed from scratch.
+	ErrHashMismatch = errors.New("Board hash does not match the recomputed hash")
+
+	// Zobrist keys for every piece (6 types * 2 colors) on every square (8x8 index).
+	zobristPieces [12][64]uint64
+	// Zobrist key that is added when white is to move.
+	zobristWhite uint64
+	// Zobrist keys for the castling rights [BLACK, WHITE].
+	zobristCastleShort [2]uint64
+	zobristCastleLong  [2]uint64
+	// Zobrist keys for the file of an en passent square.
+	zobristEpFile [8]uint64
+)
+
+func init() {
+	populateZobristKeys()
+}
+
+func populateZobristKeys() {
+	// A fixed seed keeps the keys (and therefore all hashes) equal between runs.
+	seed := uint64(0x9E3779B97F4A7C15)
+	// xorshift64* pseudo random number generator.
+	next := func() uint64 {
+		seed ^= seed >> 12
+		seed ^= seed << 25
+		seed ^= seed >> 27
+		return seed * 2685821657736338717
+	}
+
+	for p := 0; p < 12; p++ {
+		for sq := 0; sq < 64; sq++ {
+			zobristPieces[p][sq] = next()
+		}
+	}
+	zobristWhite = next()
+	for color := BLACK; color <= WHITE; color++ {
+		zobristCastleShort[color] = next()
+		zobristCastleLong[color] = next()
+	}
+	for f := 0; f < 8; f++ {
+		zobristEpFile[f] = next()
+	}
+}
+
+// zobristPiece returns the key for a piece (including its color) on a 0x88 square.
+func zobristPiece(piece Piece, sq Square) uint64 {
+	// Piece types are single bits from PAWN (bit 1) to KING (bit 6).
+	idx := 2*(bits.TrailingZeros8(uint8(piece&PIECE_MASK))-1) + int(piece.PieceColor())
+	return zobristPieces[idx][sq.To8x8()]
+}
+
+// zobristState returns the combined key for the castling rights and the en passent square.
+func (b *Board) zobristState() uint64 {
+	key := uint64(0)
+	for color := BLACK; color <= WHITE; color++ {
+		if b.CastleShort[color] {
+			key ^= zobristCastleShort[color]
+		}
+		if b.CastleLong[color] {
+			key ^= zobristCastleLong[color]
+		}
+	}
+	if b.EpSquare != OTB {
+		key ^= zobristEpFile[b.EpSquare.File()]
+	}
+
+	return key
+}
+
+// ComputeHash calculates the Zobrist hash of the board from scratch.
+// During play the hash is updated incrementally and stored in Board.Hash.
+func (b *Board) ComputeHash() uint64 {
+	key := b.zobristState()
+	if b.Player == WHITE {
+		key ^= zobristWhite
+	}
+	for _, sq := range Lookup0x88 {
+		piece := b.Squares[sq]
+		if !piece.IsEmpty() {
+			key ^= zobristPiece(piece, sq)
+		}
+	}
+
+	return key
+}
+
+// ValidateHash is a debug check that compares the incrementally updated
+// hash with a full recomputation. It returns ErrHashMismatch if they differ.
+func (b *Board) ValidateHash() error {
+	if b.Hash != b.ComputeHash() {
+		return ErrHashMismatch
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package chesskimo
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// TestZobristIncremental plays random games and compares the incrementally
+// updated hash with a full recomputation after every move and takeback.
+func TestZobristIncremental(t *testing.T) {
+	fens := []string{
+		"rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR w KQkq - 0 1",
+		"r3k2r/p1ppqpb1/bn2pnp1/3PN3/1p2P3/2N2Q1p/PPPBBPPP/R3K2R w KQkq - 0 1",
+		"n1n5/PPPk4/8/8/8/8/4Kppp/5N1N b - - 0 1",
+		"r3k2r/Pppp1ppp/1b3nbN/nP6/BBP1P3/q4N2/Pp1P2PP/R2Q1RK1 w kq - 0 1",
+		"8/2p5/3p4/KP5r/1R3p1k/8/4P1P1/8 w - - 0 0",
+	}
+
+	rng := rand.New(rand.NewSource(42))
+	board := NewBoard()
+	mlist := MoveList{}
+
+	for _, fen := range fens {
+		for game := 0; game < 20; game++ {
+			board.SetFEN(fen)
+			undos := []MoveUndo{}
+			for ply := 0; ply < 80; ply++ {
+				mlist.Clear()
+				board.GenerateAllLegalMoves(&mlist)
+				if mlist.Size == 0 {
+					break
+				}
+				move := mlist.Moves[rng.Intn(int(mlist.Size))]
+				undos = append(undos, board.MakeLegalMove(move))
+				if err := board.ValidateHash(); err != nil {
+					t.Fatalf("%s after move %s in game from FEN %s\n%s", err.Error(), move.MiniNotation(), fen, &board)
+				}
+			}
+			for i := len(undos) - 1; i >= 0; i-- {
+				board.UnmakeMove(undos[i])
+				if err := board.ValidateHash(); err != nil {
+					t.Fatalf("%s after taking back move %s in game from FEN %s\n%s", err.Error(), undos[i].Move.MiniNotation(), fen, &board)
+				}
+			}
+		}
+	}
+}
+
+// TestZobristTransposition tests if equal positions have equal hashes.
+func TestZobristTransposition(t *testing.T) {
+	board := NewBoard()
+	start := board.Hash
+
+	// Knights move out and back again.
+	for _, m := range []BitMove{NewBitMove(0x06, 0x25, NONE), NewBitMove(0x76, 0x55, NONE), NewBitMove(0x25, 0x06, NONE), NewBitMove(0x55, 0x76, NONE)} {
+		board.MakeLegalMove(m)
+	}
+	if board.Hash != start {
+		t.Fatalf("Hash of the starting position is %x but %x after knight moves\n", start, board.Hash)
+	}
+
+	// The side to move changes the hash.
+	board.SetFEN("rnbqkbnr/pppppppp/8/8/8/8/PPPPPPPP/RNBQKBNR b KQkq - 0 1")
+	if board.Hash == start {
+		t.Fatalf("Hash with black to move equals the hash of the starting position\n")
+	}
+}